@@ -0,0 +1,169 @@
+package pipe
+
+import "sync"
+
+// SlowConsumerPolicy defines what happens with the output of [Broadcast] when its consumer can't keep up.
+type SlowConsumerPolicy int
+
+const (
+	// BlockAll waits until the lagging output has free space, so all other outputs wait too.
+	BlockAll SlowConsumerPolicy = iota
+	// DropLagging drops the message for the lagging output only.
+	DropLagging
+	// DisconnectLagging closes the lagging output after its buffered messages are sent,
+	// the output doesn't receive new messages anymore.
+	DisconnectLagging
+	// GrowLagging grows the buffer of the lagging output without limit, so no output waits for others
+	// and no messages are dropped, but the memory grows while the output lags.
+	GrowLagging
+)
+
+// Broadcast takes a number of output channels, slow consumer policy and input channel, and forwards
+// the input messages to all output channels.
+// Every output has its own buffer of the input channel capacity (at least 1) and its own goroutine,
+// so no goroutines are created per message. If the buffer of an output is full then the output is lagging
+// and the message is handled by the slow consumer policy.
+// Every output receives messages in the same order as the input.
+// If input channel is closed then all output channels are closed after their buffers are sent.
+// Creates new channels with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 2) with values [1, 2, 3, 4, 5, 6, 7, 8]
+//
+//	outs := Broadcast(2, DropLagging, input)
+//
+//	// outs[0] is read immediately, outs[1] is read after input is closed
+//	// outs[0]: [1, 2, 3, 4, 5, 6, 7, 8]
+//	// outs[1]: [1, 2, 3, 4, 5]
+func Broadcast[T any](n int, policy SlowConsumerPolicy, in <-chan T) []<-chan T {
+	size := cap(in)
+	if size < 1 {
+		size = 1
+	}
+	outs := make([]*broadcastOutput[T], n)
+	for i := 0; i < n; i++ {
+		outs[i] = &broadcastOutput[T]{
+			out:   make(chan T, cap(in)),
+			ready: make(chan struct{}, 1),
+			space: make(chan struct{}, 1),
+		}
+		go outs[i].forward()
+	}
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				for i := 0; i < n; i++ {
+					outs[i].push(in, size, policy)
+				}
+			} else {
+				for i := 0; i < n; i++ {
+					outs[i].close()
+				}
+				break
+			}
+		}
+	}()
+
+	outsR := make([]<-chan T, n)
+	for i := 0; i < n; i++ {
+		outsR[i] = outs[i].out
+	}
+	return outsR
+}
+
+// broadcastOutput is the buffer of the [Broadcast] output. The input goroutine pushes messages to the queue
+// and the output goroutine forwards them to the output channel, they wake each other by ready and space channels.
+// If done channel is closed then the output goroutine drops the queue and closes the output channel,
+// it's nil for [Broadcast].
+type broadcastOutput[T any] struct {
+	mu     sync.Mutex
+	queue  ring[T]
+	closed bool
+	out    chan T
+	ready  chan struct{}
+	space  chan struct{}
+	done   chan struct{}
+}
+
+// push adds the message to the queue according to the policy, if the queue has the given size or more
+// then the output is lagging.
+func (o *broadcastOutput[T]) push(data T, size int, policy SlowConsumerPolicy) {
+	for {
+		o.mu.Lock()
+		if o.closed {
+			o.mu.Unlock()
+			return
+		}
+		if o.queue.len() < size || policy == GrowLagging {
+			o.queue.push(data)
+			o.mu.Unlock()
+			notify(o.ready)
+			return
+		}
+		switch policy {
+		case DropLagging:
+			o.mu.Unlock()
+			return
+		case DisconnectLagging:
+			o.closed = true
+			o.mu.Unlock()
+			notify(o.ready)
+			return
+		}
+		o.mu.Unlock()
+		<-o.space
+	}
+}
+
+// close closes the output after all queued messages are sent.
+func (o *broadcastOutput[T]) close() {
+	o.mu.Lock()
+	o.closed = true
+	o.mu.Unlock()
+	notify(o.ready)
+}
+
+// forward sends queued messages to the output channel until the output is closed or done.
+func (o *broadcastOutput[T]) forward() {
+	defer close(o.out)
+	for {
+		o.mu.Lock()
+		if o.queue.len() == 0 {
+			closed := o.closed
+			o.mu.Unlock()
+			if closed {
+				return
+			}
+			select {
+			case <-o.ready:
+			case <-o.done:
+				return
+			}
+			continue
+		}
+		data := o.queue.shift()
+		o.mu.Unlock()
+		notify(o.space)
+		select {
+		case o.out <- data:
+		case <-o.done:
+			return
+		}
+	}
+}
+
+// notify wakes the goroutine waiting on the signal channel with capacity 1, it never blocks.
+func notify(signal chan struct{}) {
+	select {
+	case signal <- struct{}{}:
+	default:
+	}
+}
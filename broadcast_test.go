@@ -0,0 +1,128 @@
+package pipe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestBroadcast(t *testing.T) {
+	t.Run("BlockAll", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := test.Generator(0, 64, 16)
+
+			pipes := Broadcast(10, BlockAll, pipe)
+			for i := range pipes {
+				pipes[i], epipe = test.AssertCount(fmt.Sprintf("count %d", i), pipes[i], epipe, 64)
+				pipes[i], epipe = test.AssertOrderAsc(fmt.Sprintf("ordering %d", i), pipes[i], epipe)
+			}
+
+			return pipes, epipe
+		})
+	})
+
+	t.Run("Unbuffered", func(t *testing.T) {
+		for _, policy := range []SlowConsumerPolicy{BlockAll, DropLagging, DisconnectLagging, GrowLagging} {
+			in := make(chan int)
+			outs := Broadcast(2, policy, in)
+			for i := 0; i < 200; i++ {
+				in <- i
+				for j := range outs {
+					select {
+					case v := <-outs[j]:
+						if v != i {
+							t.Fatalf("policy %d: expected %d in output %d, got %d", policy, i, j, v)
+						}
+					case <-time.After(time.Second):
+						t.Fatalf("policy %d: message %d is lost in output %d", policy, i, j)
+					}
+				}
+			}
+			close(in)
+		}
+	})
+
+	t.Run("GrowLagging", func(t *testing.T) {
+		in := make(chan int)
+		outs := Broadcast(2, GrowLagging, in)
+		for i := 0; i < 100; i++ {
+			in <- i
+			if v := <-outs[0]; v != i {
+				t.Fatalf("expected %d in the first output, got %d", i, v)
+			}
+		}
+		close(in)
+
+		count := 0
+		for v := range outs[1] {
+			if v != count {
+				t.Fatalf("expected %d in the lagging output, got %d", count, v)
+			}
+			count++
+		}
+		if count != 100 {
+			t.Errorf("expected 100 messages in the lagging output, got %d", count)
+		}
+	})
+
+	// lagging sends 32 messages in lockstep with the first output while the second output isn't read.
+	lagging := func(t *testing.T, policy SlowConsumerPolicy) (chan int, <-chan int) {
+		in := make(chan int, 2)
+		outs := Broadcast(2, policy, in)
+		for i := 0; i < 32; i++ {
+			in <- i
+			if v := <-outs[0]; v != i {
+				t.Fatalf("expected %d in the first output, got %d", i, v)
+			}
+		}
+		return in, outs[1]
+	}
+
+	collect := func(t *testing.T, out <-chan int) []int {
+		var got []int
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case v, ok := <-out:
+				if !ok {
+					return got
+				}
+				got = append(got, v)
+			case <-timeout:
+				t.Fatal("lagging output isn't closed")
+			}
+		}
+	}
+
+	t.Run("DropLagging", func(t *testing.T) {
+		in, out := lagging(t, DropLagging)
+		close(in)
+
+		got := collect(t, out)
+		if len(got) < 2 || len(got) > 5 {
+			t.Errorf("expected 2..5 messages in the lagging output, got %v", got)
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i] <= got[i-1] {
+				t.Errorf("expected ascending order, got %v", got)
+			}
+		}
+	})
+
+	t.Run("DisconnectLagging", func(t *testing.T) {
+		in, out := lagging(t, DisconnectLagging)
+		defer close(in)
+
+		got := collect(t, out)
+		if len(got) < 2 || len(got) > 5 {
+			t.Errorf("expected 2..5 messages in the lagging output, got %v", got)
+		}
+		for i, v := range got {
+			if v != i {
+				t.Errorf("expected %d, got %d", i, v)
+			}
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package pipe
+
+import "sync/atomic"
+
+// OverflowPolicy defines what happens with the message when the buffer is full.
+type OverflowPolicy int
+
+const (
+	// Block waits until the buffer has free space, the producer is blocked.
+	Block OverflowPolicy = iota
+	// DropNewest drops the received message.
+	DropNewest
+	// DropOldest drops the oldest message of the buffer to free space for the received one.
+	DropOldest
+	// Latest keeps only the latest received message, the buffer size is always 1.
+	Latest
+)
+
+// Buffered is the stage created by [Buffer].
+type Buffered[T any] struct {
+	dropped uint64
+	length  int64
+	out     chan T
+}
+
+// Chan returns the output channel of the stage.
+func (b *Buffered[T]) Chan() <-chan T {
+	return b.out
+}
+
+// Len returns the number of messages in the buffer.
+func (b *Buffered[T]) Len() int {
+	return int(atomic.LoadInt64(&b.length))
+}
+
+// Dropped returns the total number of dropped messages.
+func (b *Buffered[T]) Dropped() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+// Buffer takes message and keeps it in the buffer of the given size until the output channel is read.
+// If the buffer is full then the message is handled by the overflow policy, so a slow consumer
+// doesn't block the producer unless the policy is [Block].
+// If input channel is closed then all buffered messages are sent and output channel is closed.
+// Creates a new channel without capacity, messages are buffered inside the stage.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5] received before the consumer is ready
+//
+//	output := Buffer(3, DropOldest, input)
+//
+//	// output.Chan(): [3, 4, 5]
+//	// output.Dropped(): 2
+func Buffer[T any](size int, policy OverflowPolicy, in <-chan T) *Buffered[T] {
+	if policy == Latest {
+		size = 1
+	}
+	if size < 1 {
+		panic("buffer size must be positive")
+	}
+
+	b := &Buffered[T]{out: make(chan T)}
+
+	go func() {
+		queue := ring[T]{}
+		for in != nil || queue.len() > 0 {
+			var out chan T
+			var head T
+			if queue.len() > 0 {
+				out = b.out
+				head = queue.peek()
+			}
+			input := in
+			if policy == Block && queue.len() >= size {
+				input = nil
+			}
+
+			select {
+			case data, ok := <-input:
+				if !ok {
+					in = nil
+					break
+				}
+				if queue.len() < size {
+					queue.push(data)
+					atomic.AddInt64(&b.length, 1)
+					break
+				}
+				if policy != DropNewest {
+					queue.shift()
+					queue.push(data)
+				}
+				atomic.AddUint64(&b.dropped, 1)
+			case out <- head:
+				queue.shift()
+				atomic.AddInt64(&b.length, -1)
+			}
+		}
+		close(b.out)
+	}()
+
+	return b
+}
@@ -0,0 +1,109 @@
+package pipe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestBuffer(t *testing.T) {
+	overflow := func(t *testing.T, policy OverflowPolicy, dropped uint64, expected ...int) {
+		in := make(chan int, 8)
+		for i := 1; i <= 5; i++ {
+			in <- i
+		}
+		close(in)
+
+		b := Buffer(3, policy, in)
+		for b.Dropped() < dropped {
+			time.Sleep(time.Millisecond)
+		}
+		if b.Len() != len(expected) {
+			t.Fatalf("expected %d buffered items, got %d", len(expected), b.Len())
+		}
+
+		var results []int
+		for data := range b.Chan() {
+			results = append(results, data)
+		}
+		if fmt.Sprint(results) != fmt.Sprint(expected) || b.Dropped() != dropped {
+			t.Fatalf("expected %v with %d dropped, got %v with %d dropped", expected, dropped, results, b.Dropped())
+		}
+	}
+
+	t.Run("Block", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := test.Generator(0, 64, 16)
+
+			pipe = Buffer(4, Block, pipe).Chan()
+
+			pipe, epipe = test.AssertOrderAsc("ordering", pipe, epipe)
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 64)
+
+			return []<-chan int{pipe}, epipe
+		})
+	})
+
+	t.Run("DropNewest", func(t *testing.T) {
+		overflow(t, DropNewest, 2, 1, 2, 3)
+	})
+
+	t.Run("DropOldest", func(t *testing.T) {
+		overflow(t, DropOldest, 2, 3, 4, 5)
+	})
+
+	t.Run("Latest", func(t *testing.T) {
+		overflow(t, Latest, 4, 5)
+	})
+}
+
+func TestBufferOptions(t *testing.T) {
+	t.Run("MapWith", func(t *testing.T) {
+		b := MapWith(Options{Processing: Sequential, Size: 3, Overflow: DropOldest}, func(val int) string {
+			return fmt.Sprint(val)
+		}, filled(8, 1, 2, 3, 4, 5))
+		for b.Dropped() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+
+		var results []string
+		for data := range b.Chan() {
+			results = append(results, data)
+		}
+		if fmt.Sprint(results) != "[3 4 5]" || b.Dropped() != 2 {
+			t.Fatalf("expected [3 4 5] with 2 dropped, got %v with %d dropped", results, b.Dropped())
+		}
+	})
+
+	t.Run("FilterWith", func(t *testing.T) {
+		b := FilterWith(Options{Processing: Sync, Size: 1, Overflow: Latest}, func(val int) bool {
+			return val%2 == 0
+		}, filled(8, 1, 2, 3, 4, 5, 6))
+		for b.Dropped() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+
+		var results []int
+		for data := range b.Chan() {
+			results = append(results, data)
+		}
+		if fmt.Sprint(results) != "[6]" || b.Dropped() != 2 {
+			t.Fatalf("expected [6] with 2 dropped, got %v with %d dropped", results, b.Dropped())
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := MapWith(Options{Processing: Sync}, func(val int) int {
+				return val
+			}, test.Generator(0, 64, 16)).Chan()
+
+			pipe, epipe = test.AssertOrderAsc("ordering", pipe, epipe)
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 64)
+
+			return []<-chan int{pipe}, epipe
+		})
+	})
+}
@@ -0,0 +1,56 @@
+package pipe
+
+import "time"
+
+// Clock is a source of time for time-based functions.
+// Use it to control the time in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer creates a timer which sends the current time on its channel after the duration.
+	// Unlike [Clock.After] the timer can be stopped, so it's freed before it fires.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event timer created by [Clock.NewTimer]. C returns the channel which receives the time
+// when the timer fires, Stop prevents the timer from firing and returns false if the timer has already fired
+// or been stopped. It's an alias, so clocks of other packages can implement [Clock] without importing this one.
+type Timer = interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// SystemClock is a [Clock] based on the [time] package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// clockOrSystem returns the clock or [SystemClock] if the clock is nil.
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
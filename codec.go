@@ -0,0 +1,49 @@
+package pipe
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io"
+)
+
+// Codec creates encoders and decoders of message streams, which are used to store messages on disk.
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Encoder writes messages to the stream.
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder reads messages from the stream written by the [Encoder] of the same codec.
+type Decoder interface {
+	Decode(v any) error
+}
+
+// GobCodec is a [Codec] based on the [encoding/gob] package.
+var GobCodec Codec = gobCodec{}
+
+// JSONCodec is a [Codec] based on the [encoding/json] package.
+var JSONCodec Codec = jsonCodec{}
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder {
+	return gob.NewEncoder(w)
+}
+
+func (gobCodec) NewDecoder(r io.Reader) Decoder {
+	return gob.NewDecoder(r)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+func (jsonCodec) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
@@ -0,0 +1,293 @@
+package pipe
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// CSVRow is a decoded CSV record.
+type CSVRow[T any] struct {
+	// Line is the number of the line where the record starts, starting from 1.
+	Line  int
+	Value T
+}
+
+// csvField describes the struct field mapped to the CSV column.
+type csvField struct {
+	name  string
+	index int
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// DecodeCSV reads CSV records from the reader and sends them to the rows channel as structs.
+// The first record is a header, its columns are mapped to the struct fields by the `csv` tag
+// or by the field name if there is no tag. Fields with the `csv:"-"` tag and unknown columns are skipped.
+// Supported field types are strings, booleans, numbers and [encoding.TextUnmarshaler] implementations.
+//
+// If the record is malformed or can't be mapped to the struct then [LineError] is sent to the errors
+// channel and decoding continues with the next record.
+// If the reader fails then the error is sent to the errors channel and decoding stops.
+// When decoding stops both channels are closed.
+//
+// Be aware, both channels must be read, otherwise the decoding will be blocked.
+//
+// # Usages
+//
+//	// type User struct {
+//	//     Name string `csv:"name"`
+//	//     Age  int    `csv:"age"`
+//	// }
+//
+//	// r contains lines: "name,age", "Alice,30", "Bob,x", "Carol,25"
+//
+//	rows, errs := DecodeCSV[User](r)
+//
+//	// rows: [{2 {Alice 30}} {4 {Carol 25}}]
+//	// errs: ["line 3: column age: strconv.ParseInt: parsing "x": invalid syntax"]
+func DecodeCSV[T any](r io.Reader) (rows <-chan CSVRow[T], errs <-chan error) {
+	out := make(chan CSVRow[T], streamCapacity)
+	eout := make(chan error, streamCapacity)
+
+	go func() {
+		defer close(out)
+		defer close(eout)
+
+		fields, err := csvFields(reflect.TypeOf((*T)(nil)).Elem())
+		if err != nil {
+			eout <- err
+			return
+		}
+
+		reader := csv.NewReader(r)
+		header, err := reader.Read()
+		if err != nil {
+			if err != io.EOF {
+				eout <- &LineError{Line: 1, Err: err}
+			}
+			return
+		}
+		reader.FieldsPerRecord = len(header)
+
+		columns := make([]*csvField, len(header))
+		for i, name := range header {
+			for j := range fields {
+				if fields[j].name == name {
+					columns[i] = &fields[j]
+					break
+				}
+			}
+		}
+
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					eout <- &LineError{Line: parseErr.StartLine, Err: parseErr.Err}
+					continue
+				}
+				eout <- err
+				return
+			}
+
+			var row CSVRow[T]
+			row.Line, _ = reader.FieldPos(0)
+			if err := csvDecodeRecord(reflect.ValueOf(&row.Value).Elem(), columns, record); err != nil {
+				eout <- &LineError{Line: row.Line, Err: err}
+				continue
+			}
+			out <- row
+		}
+	}()
+
+	return out, eout
+}
+
+// EncodeCSV writes a header and every message of the input channel to the writer as CSV records.
+// The header and the columns are built from the struct fields the same way as [DecodeCSV] does.
+// The written data is flushed every time the input channel has no pending messages.
+// If input channel is closed then the data is flushed and the returned channel receives nil.
+// If writing fails then the returned channel receives the error and the input channel is read
+// to the end in the background.
+//
+// # Usages
+//
+//	// input := make(chan User, 4) with values [{Alice 30} {Carol 25}]
+//
+//	err := <-EncodeCSV(w, input)
+//
+//	// w: "name,age\nAlice,30\nCarol,25\n"
+func EncodeCSV[T any](w io.Writer, in <-chan T) <-chan error {
+	out := make(chan error, 1)
+
+	go func() {
+		buf := bufio.NewWriter(w)
+		writer := csv.NewWriter(buf)
+
+		flush := func() error {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+			return flushWriter(w)
+		}
+
+		fields, err := csvFields(reflect.TypeOf((*T)(nil)).Elem())
+		if err == nil {
+			header := make([]string, len(fields))
+			for i := range fields {
+				header[i] = fields[i].name
+			}
+			err = writer.Write(header)
+		}
+
+		record := make([]string, len(fields))
+		for err == nil {
+			if data, ok := <-in; ok {
+				value := reflect.ValueOf(data)
+				for i := range fields {
+					if record[i], err = csvFormatField(value.Field(fields[i].index)); err != nil {
+						err = fmt.Errorf("column %s: %w", fields[i].name, err)
+						break
+					}
+				}
+				if err == nil {
+					err = writer.Write(record)
+				}
+				if err == nil && len(in) == 0 {
+					err = flush()
+				}
+			} else {
+				err = flush()
+				break
+			}
+		}
+
+		if err != nil {
+			go drain(in)
+		}
+		out <- err
+		close(out)
+	}()
+
+	return out
+}
+
+// csvFields returns the fields of the struct type which are mapped to CSV columns.
+func csvFields(t reflect.Type) ([]csvField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("csv: %v is not a struct", t)
+	}
+
+	var fields []csvField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, csvField{name: name, index: i})
+	}
+	return fields, nil
+}
+
+// csvDecodeRecord sets the struct fields from the record columns.
+func csvDecodeRecord(value reflect.Value, columns []*csvField, record []string) error {
+	for i, column := range columns {
+		if column == nil {
+			continue
+		}
+		if err := csvParseField(value.Field(column.index), record[i]); err != nil {
+			return fmt.Errorf("column %s: %w", column.name, err)
+		}
+	}
+	return nil
+}
+
+// csvParseField sets the field value from the string.
+func csvParseField(field reflect.Value, s string) error {
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(v)
+	default:
+		return fmt.Errorf("unsupported type %v", field.Type())
+	}
+	return nil
+}
+
+// csvFormatField returns the string representation of the field value.
+func csvFormatField(field reflect.Value) (string, error) {
+	if field.Type().Implements(textMarshalerType) {
+		text, err := field.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if reflect.PtrTo(field.Type()).Implements(textMarshalerType) {
+		ptr := reflect.New(field.Type())
+		ptr.Elem().Set(field)
+		text, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'g', -1, field.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported type %v", field.Type())
+	}
+}
@@ -0,0 +1,72 @@
+package pipe
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type csvUser struct {
+	Name    string        `csv:"name"`
+	Age     int           `csv:"age"`
+	Score   float64       `csv:"score"`
+	Timeout time.Duration `csv:"-"`
+	Active  bool
+}
+
+func TestDecodeCSV(t *testing.T) {
+	r := strings.NewReader("name,age,Active,unknown\nAlice,30,true,x\nBob,x,false,x\nEve,1\nCarol,25,false,x\n")
+	rows, errs := DecodeCSV[csvUser](r)
+
+	var lines []int
+	done := make(chan struct{})
+	go func() {
+		for err := range errs {
+			var lineErr *LineError
+			if !errors.As(err, &lineErr) {
+				t.Errorf("expected LineError, got %v", err)
+				continue
+			}
+			lines = append(lines, lineErr.Line)
+		}
+		close(done)
+	}()
+
+	var users []CSVRow[csvUser]
+	for row := range rows {
+		users = append(users, row)
+	}
+	<-done
+
+	if len(users) != 2 {
+		t.Fatalf("expected 2 rows, got %v", users)
+	}
+	if users[0].Line != 2 || users[0].Value != (csvUser{Name: "Alice", Age: 30, Active: true}) {
+		t.Fatalf("unexpected row %v", users[0])
+	}
+	if users[1].Line != 5 || users[1].Value.Name != "Carol" {
+		t.Fatalf("unexpected row %v", users[1])
+	}
+	if len(lines) != 2 || lines[0] != 3 || lines[1] != 4 {
+		t.Fatalf("expected errors on lines 3 and 4, got %v", lines)
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	in := make(chan csvUser, 4)
+	in <- csvUser{Name: "Alice", Age: 30, Score: 1.5, Active: true}
+	in <- csvUser{Name: "Bob, Jr", Age: 25}
+	close(in)
+
+	buf := &bytes.Buffer{}
+	if err := <-EncodeCSV(buf, MapSync(func(user csvUser) csvUser { return user }, in)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "name,age,score,Active\nAlice,30,1.5,true\n\"Bob, Jr\",25,0,false\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
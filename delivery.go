@@ -0,0 +1,299 @@
+package pipe
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNacked is passed to the nack callback of [Delivery] if the message was rejected without an error.
+var ErrNacked = errors.New("pipe: delivery is rejected")
+
+// Delivery is the message which must be acknowledged after it's processed.
+// Functions of the package track all messages derived from the delivery: copies made by [SplitDelivery]
+// must be acknowledged separately, and the source is acknowledged only when every copy is acknowledged.
+// If any copy is rejected then the source is rejected when all copies are done.
+// Only the first [Delivery.Ack] or [Delivery.Nack] of every copy has an effect.
+type Delivery[T any] struct {
+	Value   T
+	tracker *deliveryTracker
+	settled *int32
+}
+
+// deliveryTracker counts unfinished copies of the delivery.
+type deliveryTracker struct {
+	refs   int32
+	failed int32
+	once   sync.Once
+	err    error
+	ack    func()
+	nack   func(error)
+}
+
+// NewDelivery creates a new delivery with the value. The ack callback is called when the message and
+// all its copies are acknowledged, otherwise the nack callback is called with the first error.
+// Any of the callbacks can be nil.
+func NewDelivery[T any](value T, ack func(), nack func(error)) Delivery[T] {
+	return Delivery[T]{Value: value, tracker: &deliveryTracker{refs: 1, ack: ack, nack: nack}, settled: new(int32)}
+}
+
+// Ack acknowledges the message is processed. Every copy of the message must be acknowledged or
+// rejected, next calls on the same copy have no effect.
+func (d Delivery[T]) Ack() {
+	d.settle(nil)
+}
+
+// Nack rejects the message with the error. If the error is nil then [ErrNacked] is used.
+// Every copy of the message must be acknowledged or rejected, next calls on the same copy have no effect.
+func (d Delivery[T]) Nack(err error) {
+	if err == nil {
+		err = ErrNacked
+	}
+	d.settle(err)
+}
+
+func (d Delivery[T]) settle(err error) {
+	t := d.tracker
+	if t == nil || !atomic.CompareAndSwapInt32(d.settled, 0, 1) {
+		return
+	}
+	if err != nil {
+		t.once.Do(func() {
+			t.err = err
+		})
+		atomic.StoreInt32(&t.failed, 1)
+	}
+	if atomic.AddInt32(&t.refs, -1) != 0 {
+		return
+	}
+	if atomic.LoadInt32(&t.failed) == 0 {
+		if t.ack != nil {
+			t.ack()
+		}
+	} else if t.nack != nil {
+		t.nack(t.err)
+	}
+}
+
+// copy returns the new copy of the message which is settled separately, it must be registered by retain.
+func (d Delivery[T]) copy() Delivery[T] {
+	return Delivery[T]{Value: d.Value, tracker: d.tracker, settled: new(int32)}
+}
+
+// retain registers n more copies of the message.
+func (d Delivery[T]) retain(n int) Delivery[T] {
+	if d.tracker != nil && n > 0 {
+		atomic.AddInt32(&d.tracker.refs, int32(n))
+	}
+	return d
+}
+
+// deliveryResult is the delivery converted by the map function, failed deliveries are dropped.
+type deliveryResult[T any] struct {
+	delivery Delivery[T]
+	ok       bool
+}
+
+// MapDelivery takes delivery and converts its value into another type by map function.
+// If map function returns an error then the delivery is rejected by [Delivery.Nack] and dropped.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Delivery[string], 4) with random values ["1", "x", "3"]
+//
+//	output := MapDelivery(strconv.Atoi, input)
+//
+//	// output: [3, 1], "x" is rejected
+func MapDelivery[Tin, Tout any](mapper func(Tin) (Tout, error), in <-chan Delivery[Tin]) <-chan Delivery[Tout] {
+	return deliveryResults(Map(deliveryMapper(mapper), in))
+}
+
+// MapDeliverySync takes delivery and converts its value into another type by map function.
+// If map function returns an error then the delivery is rejected by [Delivery.Nack] and dropped.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [MapDelivery]
+//
+//	// output: [1, 3], "x" is rejected
+func MapDeliverySync[Tin, Tout any](mapper func(Tin) (Tout, error), in <-chan Delivery[Tin]) <-chan Delivery[Tout] {
+	return deliveryResults(MapSync(deliveryMapper(mapper), in))
+}
+
+// MapDeliverySequential takes delivery and converts its value into another type by map function.
+// If map function returns an error then the delivery is rejected by [Delivery.Nack] and dropped.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [MapDelivery]
+//
+//	// output: [1, 3], "x" is rejected
+func MapDeliverySequential[Tin, Tout any](mapper func(Tin) (Tout, error), in <-chan Delivery[Tin]) <-chan Delivery[Tout] {
+	return deliveryResults(MapSequential(deliveryMapper(mapper), in))
+}
+
+// FilterDelivery takes delivery and forwards it if filter function return positive.
+// Filtered out deliveries are acknowledged by [Delivery.Ack].
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Delivery[int], 4) with random values [1, 2, 3, 4]
+//
+//	output := FilterDelivery(func(value int) bool {
+//	    return value % 2 == 0
+//	}, input)
+//
+//	// output: [4, 2], 1 and 3 are acknowledged
+func FilterDelivery[T any](filter func(T) bool, in <-chan Delivery[T]) <-chan Delivery[T] {
+	return Filter(deliveryFilter(filter), in)
+}
+
+// FilterDeliverySync takes delivery and forwards it if filter function return positive.
+// Filtered out deliveries are acknowledged by [Delivery.Ack].
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [FilterDelivery]
+//
+//	// output: [2, 4], 1 and 3 are acknowledged
+func FilterDeliverySync[T any](filter func(T) bool, in <-chan Delivery[T]) <-chan Delivery[T] {
+	return FilterSync(deliveryFilter(filter), in)
+}
+
+// FilterDeliverySequential takes delivery and forwards it if filter function return positive.
+// Filtered out deliveries are acknowledged by [Delivery.Ack].
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [FilterDelivery]
+//
+//	// output: [2, 4], 1 and 3 are acknowledged
+func FilterDeliverySequential[T any](filter func(T) bool, in <-chan Delivery[T]) <-chan Delivery[T] {
+	return FilterSequential(deliveryFilter(filter), in)
+}
+
+// SplitDelivery takes a number of output channels and input channel, and forwards the input
+// deliveries to all output channels. Every copy must be acknowledged separately, the source
+// delivery is acknowledged when all copies are acknowledged.
+// If input channel is closed then all output channels are closed.
+// Creates new channels with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Delivery[int], 4) with values [1]
+//
+//	outs := SplitDelivery(2, input)
+//
+//	(<-outs[0]).Ack() // the source is not acknowledged yet
+//	(<-outs[1]).Ack() // the source is acknowledged
+func SplitDelivery[T any](n int, in <-chan Delivery[T]) []<-chan Delivery[T] {
+	return deliveryCopies(Split(n, deliveryRetainer(n, in)))
+}
+
+// SplitDeliverySync is the same as [SplitDelivery] with [SplitSync] strategies.
+func SplitDeliverySync[T any](n int, in <-chan Delivery[T]) []<-chan Delivery[T] {
+	return deliveryCopies(SplitSync(n, deliveryRetainer(n, in)))
+}
+
+// SplitDeliverySequential is the same as [SplitDelivery] with [SplitSequential] strategies.
+func SplitDeliverySequential[T any](n int, in <-chan Delivery[T]) []<-chan Delivery[T] {
+	return deliveryCopies(SplitSequential(n, deliveryRetainer(n, in)))
+}
+
+func deliveryMapper[Tin, Tout any](mapper func(Tin) (Tout, error)) func(Delivery[Tin]) deliveryResult[Tout] {
+	return func(delivery Delivery[Tin]) deliveryResult[Tout] {
+		value, err := mapper(delivery.Value)
+		if err != nil {
+			delivery.Nack(err)
+			return deliveryResult[Tout]{}
+		}
+		return deliveryResult[Tout]{delivery: Delivery[Tout]{Value: value, tracker: delivery.tracker, settled: delivery.settled}, ok: true}
+	}
+}
+
+func deliveryResults[T any](in <-chan deliveryResult[T]) <-chan Delivery[T] {
+	return MapSequential(func(result deliveryResult[T]) Delivery[T] {
+		return result.delivery
+	}, FilterSequential(func(result deliveryResult[T]) bool {
+		return result.ok
+	}, in))
+}
+
+func deliveryFilter[T any](filter func(T) bool) func(Delivery[T]) bool {
+	return func(delivery Delivery[T]) bool {
+		if filter(delivery.Value) {
+			return true
+		}
+		delivery.Ack()
+		return false
+	}
+}
+
+func deliveryRetainer[T any](n int, in <-chan Delivery[T]) <-chan Delivery[T] {
+	return MapSequential(func(delivery Delivery[T]) Delivery[T] {
+		return delivery.retain(n - 1)
+	}, in)
+}
+
+func deliveryCopies[T any](outs []<-chan Delivery[T]) []<-chan Delivery[T] {
+	for i, out := range outs {
+		outs[i] = MapSequential(func(delivery Delivery[T]) Delivery[T] {
+			return delivery.copy()
+		}, out)
+	}
+	return outs
+}
@@ -0,0 +1,76 @@
+package pipe
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestDelivery(t *testing.T) {
+	t.Run("Pipeline", func(t *testing.T) {
+		var acks, nacks int32
+		deliveries := MapSequential(func(val int) Delivery[int] {
+			return NewDelivery(val, func() {
+				atomic.AddInt32(&acks, 1)
+			}, func(error) {
+				atomic.AddInt32(&nacks, 1)
+			})
+		}, test.Generator(0, 64, 16))
+
+		mapped := MapDeliverySync(func(val int) (int, error) {
+			if val%8 == 0 {
+				return 0, errors.New("failed")
+			}
+			return val, nil
+		}, deliveries)
+		filtered := FilterDelivery(func(val int) bool {
+			return val%2 == 0
+		}, mapped)
+		outs := SplitDelivery(3, filtered)
+
+		wg := sync.WaitGroup{}
+		for _, out := range outs {
+			out := out
+			wg.Add(1)
+			go func() {
+				for delivery := range out {
+					delivery.Ack()
+				}
+				wg.Done()
+			}()
+		}
+		wg.Wait()
+
+		if acks != 56 || nacks != 8 {
+			t.Fatalf("expected 56 acks and 8 nacks, got %d and %d", acks, nacks)
+		}
+	})
+
+	t.Run("Copies", func(t *testing.T) {
+		var result error = errors.New("pending")
+		in := make(chan Delivery[int], 1)
+		in <- NewDelivery(1, func() {
+			result = nil
+		}, func(err error) {
+			result = err
+		})
+		close(in)
+
+		outs := SplitDeliverySequential(3, in)
+		errFailed := errors.New("failed")
+		first := <-outs[0]
+		first.Ack()
+		first.Ack()
+		(<-outs[1]).Nack(errFailed)
+		if result == nil || result == errFailed {
+			t.Fatalf("expected pending delivery, got %v", result)
+		}
+		(<-outs[2]).Ack()
+		if result != errFailed {
+			t.Fatalf("expected rejected delivery, got %v", result)
+		}
+	})
+}
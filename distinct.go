@@ -0,0 +1,224 @@
+package pipe
+
+import (
+	"container/list"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// DistinctConfig is the configuration of [Distinct].
+// Keys are remembered in an exact set limited by Size and TTL, or, if Bloom is set, in a Bloom filter.
+type DistinctConfig struct {
+	// Size is the maximum number of remembered keys. If it's exceeded then the least recently seen key
+	// is forgotten, or, if TTL is set, the oldest one. If it's 0 then there is no limit.
+	Size int
+	// TTL is the time the key is remembered since it was passed. A message with the forgotten key
+	// is passed again. If it's 0 then keys are remembered until they are evicted by Size.
+	TTL time.Duration
+	// Bloom is the expected number of distinct keys. If it's set then keys are remembered in a Bloom filter
+	// which uses fixed memory and never forgets keys, but a new key can be rarely taken as a duplicate.
+	// Size and TTL are ignored.
+	Bloom int
+	// FalsePositive is the probability to take a new key as a duplicate in the Bloom mode when the number
+	// of keys doesn't exceed Bloom. By default it's 0.01.
+	FalsePositive float64
+	// Clock is the source of time. By default the [SystemClock] is used.
+	Clock Clock
+}
+
+// Deduplicated is the stage created by [Distinct].
+type Deduplicated[T any] struct {
+	hits   uint64
+	misses uint64
+	out    <-chan T
+}
+
+// Chan returns the output channel of the stage.
+func (d *Deduplicated[T]) Chan() <-chan T {
+	return d.out
+}
+
+// Hits returns the number of dropped duplicates.
+func (d *Deduplicated[T]) Hits() uint64 {
+	return atomic.LoadUint64(&d.hits)
+}
+
+// Misses returns the number of passed messages with new keys.
+func (d *Deduplicated[T]) Misses() uint64 {
+	return atomic.LoadUint64(&d.misses)
+}
+
+// Distinct takes message and forwards it to the output channel only if a message with the same key
+// wasn't passed before. Keys are remembered according to the configuration.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Event, 4) with values [{1 a}, {2 b}, {1 c}, {3 d}, {2 e}]
+//
+//	output := Distinct(DistinctConfig{Size: 1000}, func(e Event) int {
+//	    return e.ID
+//	}, input)
+//
+//	// output.Chan(): [{1 a}, {2 b}, {3 d}]
+//	// output.Hits(): 2
+func Distinct[T any, K comparable](config DistinctConfig, key func(T) K, in <-chan T) *Deduplicated[T] {
+	var seen func(K) bool
+	if config.Bloom > 0 {
+		seen = newBloomSet[K](config.Bloom, config.FalsePositive).seen
+	} else {
+		seen = newKeySet[K](config.Size, config.TTL, clockOrSystem(config.Clock)).seen
+	}
+
+	d := &Deduplicated[T]{}
+	d.out = FilterSequential(func(data T) bool {
+		if seen(key(data)) {
+			atomic.AddUint64(&d.hits, 1)
+			return false
+		}
+		atomic.AddUint64(&d.misses, 1)
+		return true
+	}, in)
+
+	return d
+}
+
+// DistinctUntilChanged takes message and forwards it to the output channel only if its key differs
+// from the key of the previous message.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 1, 2, 2, 2, 1, 3, 3]
+//
+//	output := DistinctUntilChanged(func(v int) int {
+//	    return v
+//	}, input)
+//
+//	// output: [1, 2, 1, 3]
+func DistinctUntilChanged[T any, K comparable](key func(T) K, in <-chan T) <-chan T {
+	var last K
+	first := true
+	return FilterSequential(func(data T) bool {
+		k := key(data)
+		if !first && k == last {
+			return false
+		}
+		first = false
+		last = k
+		return true
+	}, in)
+}
+
+// keySet is the exact set of keys limited by size and TTL.
+type keySet[K comparable] struct {
+	size   int
+	ttl    time.Duration
+	clock  Clock
+	keys   map[K]*list.Element
+	recent *list.List
+}
+
+type keySetEntry[K comparable] struct {
+	key K
+	at  time.Time
+}
+
+func newKeySet[K comparable](size int, ttl time.Duration, clock Clock) *keySet[K] {
+	return &keySet[K]{
+		size:   size,
+		ttl:    ttl,
+		clock:  clock,
+		keys:   map[K]*list.Element{},
+		recent: list.New(),
+	}
+}
+
+// seen adds the key to the set and returns true if it was already there.
+// Without TTL the list is ordered by the last access, with TTL it's ordered by the time keys were added.
+func (s *keySet[K]) seen(key K) bool {
+	var now time.Time
+	if s.ttl > 0 {
+		now = s.clock.Now()
+		for back := s.recent.Back(); back != nil; back = s.recent.Back() {
+			entry := back.Value.(*keySetEntry[K])
+			if now.Sub(entry.at) < s.ttl {
+				break
+			}
+			s.recent.Remove(back)
+			delete(s.keys, entry.key)
+		}
+	}
+
+	if element, ok := s.keys[key]; ok {
+		if s.ttl == 0 {
+			s.recent.MoveToFront(element)
+		}
+		return true
+	}
+
+	s.keys[key] = s.recent.PushFront(&keySetEntry[K]{key: key, at: now})
+	if s.size > 0 && s.recent.Len() > s.size {
+		back := s.recent.Back()
+		s.recent.Remove(back)
+		delete(s.keys, back.Value.(*keySetEntry[K]).key)
+	}
+	return false
+}
+
+// bloomSet is the Bloom filter of keys.
+type bloomSet[K comparable] struct {
+	bits   []uint64
+	size   uint64
+	hashes int
+}
+
+func newBloomSet[K comparable](n int, p float64) *bloomSet[K] {
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	size := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if size < 64 {
+		size = 64
+	}
+	hashes := int(math.Round(float64(size) / float64(n) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &bloomSet[K]{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// seen adds the key to the set and returns true if it was probably already there.
+// Bit positions are derived from one 64 bit hash by double hashing.
+func (s *bloomSet[K]) seen(key K) bool {
+	hash := hashKey(key)
+	h1, h2 := hash&math.MaxUint32, hash>>32|1
+	found := true
+	for i := 0; i < s.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % s.size
+		if s.bits[bit/64]&(1<<(bit%64)) == 0 {
+			found = false
+			s.bits[bit/64] |= 1 << (bit % 64)
+		}
+	}
+	return found
+}
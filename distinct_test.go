@@ -0,0 +1,117 @@
+package pipe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestDistinct(t *testing.T) {
+	identity := func(v int) int {
+		return v
+	}
+	collect := func(out <-chan int) []int {
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		return got
+	}
+
+	t.Run("LRU", func(t *testing.T) {
+		stage := Distinct(DistinctConfig{Size: 2}, identity, filled(8, 1, 2, 1, 3, 2, 1))
+		if got := collect(stage.Chan()); !reflect.DeepEqual(got, []int{1, 2, 3, 2, 1}) {
+			t.Errorf("unexpected output %v", got)
+		}
+		if stage.Hits() != 1 || stage.Misses() != 5 {
+			t.Errorf("expected 1 hit and 5 misses, got %d and %d", stage.Hits(), stage.Misses())
+		}
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		clock := test.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		in := make(chan int)
+		stage := Distinct(DistinctConfig{TTL: 10 * time.Second, Clock: clock}, identity, in)
+
+		// Every duplicate is followed by a new key, so the output proves the duplicate is handled.
+		expect := func(send []int, want int) {
+			for _, v := range send {
+				in <- v
+			}
+			if got := <-stage.Chan(); got != want {
+				t.Fatalf("expected %d, got %d", want, got)
+			}
+		}
+
+		expect([]int{1}, 1)
+		clock.Advance(5 * time.Second)
+		expect([]int{1, 2}, 2)
+		clock.Advance(6 * time.Second)
+		expect([]int{2, 1}, 1)
+		close(in)
+
+		if _, ok := <-stage.Chan(); ok {
+			t.Error("expected closed output")
+		}
+		if stage.Hits() != 2 || stage.Misses() != 3 {
+			t.Errorf("expected 2 hits and 3 misses, got %d and %d", stage.Hits(), stage.Misses())
+		}
+	})
+
+	t.Run("Bloom", func(t *testing.T) {
+		in := make(chan int, 64)
+		go func() {
+			for pass := 0; pass < 2; pass++ {
+				for i := 0; i < 1000; i++ {
+					in <- i
+				}
+			}
+			close(in)
+		}()
+
+		stage := Distinct(DistinctConfig{Bloom: 1000}, identity, in)
+		got := collect(stage.Chan())
+		if len(got) < 950 || len(got) > 1000 {
+			t.Errorf("expected about 1000 messages, got %d", len(got))
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i] <= got[i-1] {
+				t.Fatalf("duplicate passed after %d: %d", got[i-1], got[i])
+			}
+		}
+		if stage.Hits()+stage.Misses() != 2000 {
+			t.Errorf("expected 2000 handled messages, got %d", stage.Hits()+stage.Misses())
+		}
+	})
+
+	t.Run("BloomCompositeKeys", func(t *testing.T) {
+		type key struct {
+			a, b string
+		}
+		in := make(chan key, 4)
+		in <- key{"a b", ""}
+		in <- key{"a", "b "}
+		in <- key{"a b", ""}
+		close(in)
+
+		stage := Distinct(DistinctConfig{Bloom: 1000}, func(k key) key {
+			return k
+		}, in)
+		var got []key
+		for k := range stage.Chan() {
+			got = append(got, k)
+		}
+		if len(got) != 2 || stage.Hits() != 1 {
+			t.Errorf("expected 2 distinct keys and 1 hit, got %v and %d", got, stage.Hits())
+		}
+	})
+
+	t.Run("UntilChanged", func(t *testing.T) {
+		out := DistinctUntilChanged(identity, filled(8, 1, 1, 2, 2, 2, 1, 3, 3))
+		if got := collect(out); !reflect.DeepEqual(got, []int{1, 2, 1, 3}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+}
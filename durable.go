@@ -0,0 +1,435 @@
+package pipe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrClosedQueue is returned by [Durable.Send] when the queue is already closed.
+var ErrClosedQueue = errors.New("pipe: send on closed queue")
+
+// defaultCompactSize is the log size of [Durable] after which it's compacted if it's not configured.
+const defaultCompactSize = 64 << 20
+
+// Kinds of log records of [Durable].
+const (
+	walItem byte = iota + 1
+	walAck
+)
+
+// walHeaderSize is the size of the record header: kind, id, payload length and checksum.
+const walHeaderSize = 1 + 8 + 4 + 4
+
+// walMaxPayload is the maximum size of the encoded message in the log.
+const walMaxPayload = 64 << 20
+
+// walTable is the CRC-32 table of record checksums.
+var walTable = crc32.MakeTable(crc32.Castagnoli)
+
+// DurableConfig configures [OpenDurable].
+type DurableConfig struct {
+	// Dir is the directory of the write-ahead log.
+	Dir string
+	// Codec encodes messages in the log. By default the [GobCodec] is used.
+	Codec Codec
+	// NoSync disables syncing the log to disk after every message, which is faster but messages
+	// can be lost if the system crashes.
+	NoSync bool
+	// CompactSize is the log size after which it's rewritten with the messages which are not acknowledged.
+	// The log is compacted only if at least half of it is acknowledged. By default it's 64 MiB.
+	CompactSize int64
+}
+
+// Durable is a queue which appends every message to the write-ahead log before it's delivered.
+// Rejected messages are delivered again, and messages which are not acknowledged are delivered again
+// when the queue is opened next time.
+// It's safe for concurrent use.
+type Durable[T any] struct {
+	config  DurableConfig
+	out     chan Delivery[T]
+	notify  chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	nextID uint64
+	// pending keeps the payloads of messages which are not acknowledged for compaction,
+	// live is the size of their records.
+	pending map[uint64][]byte
+	live    int64
+	queue   ring[durableItem[T]]
+	closed  bool
+	// failed is the error of the log which can't be restored after a failed write.
+	failed error
+}
+
+// durableItem is the message waiting for delivery.
+type durableItem[T any] struct {
+	id   uint64
+	data T
+}
+
+// OpenDurable opens the queue in the directory, and delivers messages which were not acknowledged before.
+//
+// # Usages
+//
+//	queue, err := OpenDurable[Event](DurableConfig{Dir: "/var/lib/app/queue"})
+//
+//	go func() {
+//	    for event := range input {
+//	        queue.Send(event)
+//	    }
+//	}()
+//
+//	output := Map(func(delivery Delivery[Event]) Delivery[Event] {
+//	    handle(delivery.Value)
+//	    delivery.Ack()
+//	    return delivery
+//	}, queue.Chan())
+func OpenDurable[T any](config DurableConfig) (*Durable[T], error) {
+	if config.Codec == nil {
+		config.Codec = GobCodec
+	}
+	if config.CompactSize <= 0 {
+		config.CompactSize = defaultCompactSize
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	q := &Durable[T]{
+		config:  config,
+		out:     make(chan Delivery[T]),
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		pending: map[uint64][]byte{},
+	}
+	if err := q.recover(); err != nil {
+		return nil, err
+	}
+
+	go q.deliver()
+	return q, nil
+}
+
+// Chan returns the channel of deliveries. It's closed when the queue is closed.
+func (q *Durable[T]) Chan() <-chan Delivery[T] {
+	return q.out
+}
+
+// Pending returns the number of messages which are not acknowledged.
+func (q *Durable[T]) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Send appends the message to the log and queues it for delivery.
+// If writing fails then the log is truncated back and the message is not queued.
+// Returns [ErrClosedQueue] if the queue is closed.
+func (q *Durable[T]) Send(data T) error {
+	payload, err := q.encode(data)
+	if err != nil {
+		return err
+	}
+	if len(payload) > walMaxPayload {
+		return fmt.Errorf("pipe: encoded message of %d bytes exceeds %d bytes", len(payload), walMaxPayload)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosedQueue
+	}
+	if q.failed != nil {
+		return q.failed
+	}
+
+	id := q.nextID
+	size := q.size
+	if err := q.append(walItem, id, payload); err != nil {
+		return err
+	}
+	if !q.config.NoSync {
+		if err := q.file.Sync(); err != nil {
+			q.rollback(size)
+			return err
+		}
+	}
+	q.nextID++
+	q.pending[id] = payload
+	q.live += q.size - size
+	q.queue.push(durableItem[T]{id: id, data: data})
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Close stops deliveries and closes the log. Messages which are not acknowledged will be delivered
+// when the queue is opened next time, acknowledgements after the close are ignored.
+func (q *Durable[T]) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.done)
+	q.mu.Unlock()
+
+	<-q.stopped
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.file.Close()
+}
+
+// ack appends the acknowledgement of the message to the log, or compacts the log if it's big enough.
+func (q *Durable[T]) ack(id uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	payload, ok := q.pending[id]
+	if q.closed || !ok {
+		return
+	}
+
+	delete(q.pending, id)
+	q.live -= int64(walHeaderSize + len(payload))
+	if q.failed != nil {
+		return
+	}
+	if q.size >= q.config.CompactSize && q.size >= 2*q.live && q.compact() == nil {
+		return
+	}
+	// The lost acknowledgement only leads to the repeated delivery
+	_ = q.append(walAck, id, nil)
+}
+
+// nack queues the message for delivery again.
+func (q *Durable[T]) nack(item durableItem[T]) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.pending[item.id]; q.closed || !ok {
+		return
+	}
+
+	q.queue.push(item)
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends queued messages to the output channel until the queue is closed.
+func (q *Durable[T]) deliver() {
+	defer close(q.stopped)
+	defer close(q.out)
+
+	for {
+		q.mu.Lock()
+		if q.queue.len() == 0 {
+			q.mu.Unlock()
+			select {
+			case <-q.notify:
+				continue
+			case <-q.done:
+				return
+			}
+		}
+		item := q.queue.peek()
+		q.mu.Unlock()
+
+		delivery := NewDelivery(item.data, func() {
+			q.ack(item.id)
+		}, func(error) {
+			q.nack(item)
+		})
+		select {
+		case q.out <- delivery:
+			q.mu.Lock()
+			q.queue.shift()
+			q.mu.Unlock()
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// append writes the record to the end of the log. If writing fails then the log is truncated back.
+// Must be called under the lock.
+func (q *Durable[T]) append(kind byte, id uint64, payload []byte) error {
+	record := walRecord(kind, id, payload)
+	if _, err := q.file.Write(record); err != nil {
+		q.rollback(q.size)
+		return err
+	}
+	q.size += int64(len(record))
+	return nil
+}
+
+// rollback truncates the log to the size. If it fails then the log is broken and all next sends fail.
+// Must be called under the lock.
+func (q *Durable[T]) rollback(size int64) {
+	err := q.file.Truncate(size)
+	if err == nil {
+		_, err = q.file.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		q.failed = fmt.Errorf("pipe: log is broken: %w", err)
+		return
+	}
+	q.size = size
+}
+
+// compact writes the records of pending messages to the temporary file in the order of ids, syncs it
+// and replaces the log with it. If it fails then the current log is kept.
+// Must be called under the lock.
+func (q *Durable[T]) compact() error {
+	path := filepath.Join(q.config.Dir, "wal")
+	tmp := path + ".tmp"
+
+	ids := make([]uint64, 0, len(q.pending))
+	for id := range q.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	buf := bytes.Buffer{}
+	for _, id := range ids {
+		buf.Write(walRecord(walItem, id, q.pending[id]))
+	}
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(buf.Bytes()); err == nil {
+		if err = file.Sync(); err == nil {
+			err = os.Rename(tmp, path)
+		}
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if q.file != nil {
+		q.file.Close()
+	}
+	q.file = file
+	q.size = int64(buf.Len())
+	q.live = q.size
+	return nil
+}
+
+// walRecord returns the log record with the header.
+func walRecord(kind byte, id uint64, payload []byte) []byte {
+	record := make([]byte, walHeaderSize+len(payload))
+	record[0] = kind
+	binary.BigEndian.PutUint64(record[1:], id)
+	binary.BigEndian.PutUint32(record[9:], uint32(len(payload)))
+	copy(record[walHeaderSize:], payload)
+	binary.BigEndian.PutUint32(record[13:], walChecksum(record))
+	return record
+}
+
+// walChecksum returns the checksum of the record, the checksum field itself is skipped.
+func walChecksum(record []byte) uint32 {
+	sum := crc32.Update(0, walTable, record[:13])
+	return crc32.Update(sum, walTable, record[walHeaderSize:])
+}
+
+func (q *Durable[T]) encode(data T) ([]byte, error) {
+	buf := bytes.Buffer{}
+	if err := q.config.Codec.NewEncoder(&buf).Encode(data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (q *Durable[T]) decode(payload []byte) (T, error) {
+	var data T
+	err := q.config.Codec.NewDecoder(bytes.NewReader(payload)).Decode(&data)
+	return data, err
+}
+
+// recover reads the log, queues messages which are not acknowledged and rewrites the log with them only.
+// The log is read up to the first incomplete or damaged record, the rest of the log is discarded.
+func (q *Durable[T]) recover() error {
+	path := filepath.Join(q.config.Dir, "wal")
+	payloads := map[uint64][]byte{}
+	var ids []uint64
+
+	if file, err := os.Open(path); err == nil {
+		remaining := int64(0)
+		if info, err := file.Stat(); err == nil {
+			remaining = info.Size()
+		}
+		reader := bufio.NewReader(file)
+		for {
+			header := make([]byte, walHeaderSize)
+			if _, err := io.ReadFull(reader, header); err != nil {
+				break
+			}
+			remaining -= walHeaderSize
+			length := int64(binary.BigEndian.Uint32(header[9:]))
+			if length > walMaxPayload || length > remaining {
+				break
+			}
+			record := append(header, make([]byte, length)...)
+			if _, err := io.ReadFull(reader, record[walHeaderSize:]); err != nil {
+				break
+			}
+			remaining -= length
+			if binary.BigEndian.Uint32(record[13:]) != walChecksum(record) {
+				break
+			}
+
+			id := binary.BigEndian.Uint64(record[1:])
+			switch record[0] {
+			case walItem:
+				payloads[id] = record[walHeaderSize:]
+				ids = append(ids, id)
+			case walAck:
+				delete(payloads, id)
+			}
+			if id >= q.nextID {
+				q.nextID = id + 1
+			}
+		}
+		file.Close()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, id := range ids {
+		payload, ok := payloads[id]
+		if !ok {
+			continue
+		}
+		data, err := q.decode(payload)
+		if err != nil {
+			return err
+		}
+		q.pending[id] = payload
+		q.queue.push(durableItem[T]{id: id, data: data})
+	}
+	return q.compact()
+}
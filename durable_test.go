@@ -0,0 +1,204 @@
+package pipe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDurable(t *testing.T) {
+	dir := t.TempDir()
+	open := func() *Durable[int] {
+		q, err := OpenDurable[int](DurableConfig{Dir: dir, CompactSize: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+	receive := func(q *Durable[int], n int) []Delivery[int] {
+		deliveries := make([]Delivery[int], n)
+		for i := range deliveries {
+			deliveries[i] = <-q.Chan()
+		}
+		return deliveries
+	}
+	values := func(deliveries []Delivery[int]) string {
+		result := make([]int, len(deliveries))
+		for i := range deliveries {
+			result[i] = deliveries[i].Value
+		}
+		return fmt.Sprint(result)
+	}
+
+	q := open()
+	for i := 0; i < 5; i++ {
+		if err := q.Send(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deliveries := receive(q, 5)
+	deliveries[0].Ack()
+	deliveries[2].Ack()
+	deliveries[2].Ack()
+	if q.Pending() != 3 {
+		t.Fatalf("expected 3 pending messages, got %d", q.Pending())
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Send(5); err != ErrClosedQueue {
+		t.Fatalf("expected ErrClosedQueue, got %v", err)
+	}
+
+	// Incomplete record of the crashed process
+	file, err := os.OpenFile(filepath.Join(dir, "wal"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte{walItem, 0, 0})
+	file.Close()
+
+	q = open()
+	q.Send(5)
+	deliveries = receive(q, 4)
+	if values(deliveries) != "[1 3 4 5]" {
+		t.Fatalf("expected redelivery of [1 3 4 5], got %s", values(deliveries))
+	}
+	for _, delivery := range deliveries {
+		delivery.Ack()
+	}
+	if info, err := os.Stat(filepath.Join(dir, "wal")); err != nil || info.Size() != 0 {
+		t.Fatalf("expected compacted log, got %v", err)
+	}
+	q.Close()
+
+	q = open()
+	q.Send(6)
+	(<-q.Chan()).Nack(nil)
+	if deliveries = receive(q, 1); values(deliveries) != "[6]" {
+		t.Fatalf("expected [6], got %s", values(deliveries))
+	}
+	q.Close()
+	<-Wait(q.Chan())
+}
+
+func TestDurableCompaction(t *testing.T) {
+	dir := t.TempDir()
+	config := DurableConfig{Dir: dir, NoSync: true, CompactSize: 1024}
+	q, err := OpenDurable[int](config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Send(-1); err != nil {
+		t.Fatal(err)
+	}
+	kept := <-q.Chan()
+	for i := 0; i < 2000; i++ {
+		if err := q.Send(i); err != nil {
+			t.Fatal(err)
+		}
+		(<-q.Chan()).Ack()
+
+		info, err := os.Stat(filepath.Join(dir, "wal"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 2*config.CompactSize {
+			t.Fatalf("expected compacted log, got %d bytes", info.Size())
+		}
+	}
+	if q.Pending() != 1 {
+		t.Fatalf("expected 1 pending message, got %d", q.Pending())
+	}
+	q.Close()
+
+	q, err = OpenDurable[int](config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery := <-q.Chan(); delivery.Value != kept.Value {
+		t.Fatalf("expected redelivery of %d, got %d", kept.Value, delivery.Value)
+	}
+	q.Close()
+}
+
+func TestDurableRecovery(t *testing.T) {
+	dir := t.TempDir()
+	q, err := OpenDurable[int](DurableConfig{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := q.Send(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	payload, err := q.encode(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	record := func(id uint64, damaged bool) []byte {
+		record := make([]byte, walHeaderSize+len(payload))
+		record[0] = walItem
+		binary.BigEndian.PutUint64(record[1:], id)
+		binary.BigEndian.PutUint32(record[9:], uint32(len(payload)))
+		copy(record[walHeaderSize:], payload)
+		binary.BigEndian.PutUint32(record[13:], walChecksum(record))
+		if damaged {
+			record[len(record)-1] ^= 0xff
+		}
+		return record
+	}
+	reopen := func(tail ...[]byte) string {
+		file, err := os.OpenFile(filepath.Join(dir, "wal"), os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, data := range tail {
+			file.Write(data)
+		}
+		file.Close()
+
+		q, err := OpenDurable[int](DurableConfig{Dir: dir})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer q.Close()
+		result := make([]int, q.Pending())
+		for i := range result {
+			result[i] = (<-q.Chan()).Value
+		}
+		return fmt.Sprint(result)
+	}
+
+	if got := reopen(record(10, false), record(11, true), record(12, false)); got != "[0 1 2 7]" {
+		t.Fatalf("expected recovery up to the damaged record, got %s", got)
+	}
+
+	huge := make([]byte, walHeaderSize)
+	huge[0] = walItem
+	binary.BigEndian.PutUint32(huge[9:], 0xffffffff)
+	if got := reopen(huge, record(13, false)); got != "[0 1 2 7]" {
+		t.Fatalf("expected recovery up to the record with invalid length, got %s", got)
+	}
+
+	t.Run("BrokenLog", func(t *testing.T) {
+		q, err := OpenDurable[int](DurableConfig{Dir: t.TempDir()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.file.Close()
+		if err := q.Send(1); err == nil {
+			t.Fatal("expected write error")
+		}
+		if err := q.Send(2); err == nil || q.Pending() != 0 {
+			t.Fatalf("expected broken log error and no pending messages, got %v", err)
+		}
+		q.Close()
+	})
+}
@@ -0,0 +1,180 @@
+package pipe
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrBufferFull is the error of [ElasticBuffer.TrySend] when the message doesn't fit into the limit.
+var ErrBufferFull = errors.New("pipe: buffer is full")
+
+// Sizer is implemented by messages which know their size in bytes.
+// The size is used by [Elastic] to limit the buffer by bytes.
+type Sizer interface {
+	Size() int
+}
+
+// ElasticConfig configures [Elastic].
+type ElasticConfig struct {
+	// MaxItems is the maximum number of buffered messages. If it's 0 then there is no limit.
+	MaxItems int
+	// MaxBytes is the maximum total size of buffered messages, only messages implementing [Sizer]
+	// are counted. If it's 0 then there is no limit.
+	MaxBytes int
+	// Reject drops the input message which doesn't fit into the limit, instead of blocking the producer.
+	// Dropped messages are counted by [ElasticBuffer.Rejected].
+	Reject bool
+}
+
+// ElasticBuffer is the stage created by [Elastic].
+type ElasticBuffer[T any] struct {
+	items    int64
+	bytes    int64
+	rejected int64
+	config   ElasticConfig
+	out      chan T
+	wake     chan struct{}
+	mu       sync.Mutex
+	queue    ring[T]
+	closed   bool
+}
+
+// Chan returns the output channel of the stage.
+func (b *ElasticBuffer[T]) Chan() <-chan T {
+	return b.out
+}
+
+// Len returns the number of buffered messages.
+func (b *ElasticBuffer[T]) Len() int {
+	return int(atomic.LoadInt64(&b.items))
+}
+
+// Bytes returns the total size of buffered messages.
+func (b *ElasticBuffer[T]) Bytes() int {
+	return int(atomic.LoadInt64(&b.bytes))
+}
+
+// Rejected returns the number of input messages dropped by the limit with [ElasticConfig.Reject].
+func (b *ElasticBuffer[T]) Rejected() int {
+	return int(atomic.LoadInt64(&b.rejected))
+}
+
+// TrySend puts the message into the buffer without waiting. It returns [ErrBufferFull] if the message
+// doesn't fit into the limit, or [ErrClosedPipe] if the stage is already closed.
+func (b *ElasticBuffer[T]) TrySend(data T) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosedPipe
+	}
+	size := sizeOf(data)
+	if b.full(size) {
+		return ErrBufferFull
+	}
+	b.push(data, size)
+	notify(b.wake)
+	return nil
+}
+
+// full reports whether the message of the size doesn't fit into the limit. The caller must hold the lock.
+func (b *ElasticBuffer[T]) full(size int) bool {
+	items := b.queue.len()
+	return items > 0 && ((b.config.MaxItems > 0 && items+1 > b.config.MaxItems) ||
+		(b.config.MaxBytes > 0 && b.Bytes()+size > b.config.MaxBytes))
+}
+
+// push buffers the message. The caller must hold the lock.
+func (b *ElasticBuffer[T]) push(data T, size int) {
+	b.queue.push(data)
+	atomic.AddInt64(&b.items, 1)
+	atomic.AddInt64(&b.bytes, int64(size))
+}
+
+// Elastic takes message and keeps it in the buffer which grows as needed until the output channel is read.
+// If the limit is reached then the producer is blocked until the buffer has free space, or, if the config
+// has Reject flag, the message which doesn't fit is dropped and counted, and next messages are read as usual.
+// Producers which must know about the rejection can use [ElasticBuffer.TrySend], it returns [ErrBufferFull].
+// The limit can be exceeded by the size of one message, so a message bigger than the limit can pass.
+// If input channel is closed then all buffered messages are sent and output channel is closed.
+// Creates a new channel without capacity, messages are buffered inside the stage.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//
+// # Usages
+//
+//	// input := make(chan Packet) with bursts of packets
+//
+//	output := Elastic(ElasticConfig{MaxBytes: 64 << 20}, input)
+//
+//	// output.Chan(): all packets, the producer waits only if 64 MiB are buffered
+//	// output.Len(), output.Bytes(): the current buffer size
+//
+//	output := Elastic(ElasticConfig{MaxBytes: 64 << 20, Reject: true}, input)
+//
+//	// output.Chan(): packets which fit into 64 MiB, others are dropped
+//	// output.TrySend(packet): ErrBufferFull if the packet doesn't fit
+//	// output.Rejected(): the number of dropped input packets
+func Elastic[T any](config ElasticConfig, in <-chan T) *ElasticBuffer[T] {
+	b := &ElasticBuffer[T]{config: config, out: make(chan T), wake: make(chan struct{}, 1)}
+
+	go func() {
+		for {
+			b.mu.Lock()
+			if in == nil && b.queue.len() == 0 {
+				b.closed = true
+				b.mu.Unlock()
+				break
+			}
+			var out chan T
+			var head T
+			if b.queue.len() > 0 {
+				out = b.out
+				head = b.queue.peek()
+			}
+			input := in
+			if !config.Reject && b.full(1) {
+				input = nil
+			}
+			b.mu.Unlock()
+
+			select {
+			case data, ok := <-input:
+				if !ok {
+					in = nil
+					break
+				}
+				size := sizeOf(data)
+				b.mu.Lock()
+				if config.Reject && b.full(size) {
+					atomic.AddInt64(&b.rejected, 1)
+				} else {
+					b.push(data, size)
+				}
+				b.mu.Unlock()
+			case out <- head:
+				b.mu.Lock()
+				b.queue.shift()
+				atomic.AddInt64(&b.items, -1)
+				atomic.AddInt64(&b.bytes, -int64(sizeOf(head)))
+				b.mu.Unlock()
+			case <-b.wake:
+			}
+		}
+		close(b.out)
+	}()
+
+	return b
+}
+
+// sizeOf returns the size of the message if it implements [Sizer], otherwise 0.
+func sizeOf[T any](data T) int {
+	if sizer, ok := any(data).(Sizer); ok {
+		return sizer.Size()
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+package pipe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+type sizedString string
+
+func (s sizedString) Size() int {
+	return len(s)
+}
+
+func TestElastic(t *testing.T) {
+	t.Run("Unlimited", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := test.Generator(0, 64, 0)
+
+			pipe = Elastic(ElasticConfig{}, pipe).Chan()
+
+			pipe, epipe = test.AssertOrderAsc("ordering", pipe, epipe)
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 64)
+
+			return []<-chan int{pipe}, epipe
+		})
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		in := make(chan int, 16)
+		for i := 0; i < 10; i++ {
+			in <- i
+		}
+		close(in)
+
+		b := Elastic(ElasticConfig{MaxItems: 3}, in)
+		for b.Len() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(time.Millisecond)
+		if b.Len() != 3 || len(in) != 7 {
+			t.Fatalf("expected 3 buffered and 7 waiting items, got %d and %d", b.Len(), len(in))
+		}
+
+		count := 0
+		for range b.Chan() {
+			count++
+		}
+		if count != 10 || b.Rejected() != 0 {
+			t.Fatalf("expected 10 items, got %d, %d rejected", count, b.Rejected())
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		in := make(chan sizedString, 4)
+		in <- "aa"
+		in <- "bb"
+		in <- "cc"
+		in <- "dd"
+		close(in)
+
+		b := Elastic(ElasticConfig{MaxBytes: 5, Reject: true}, in)
+		for b.Rejected() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		if b.Bytes() != 4 {
+			t.Fatalf("expected 4 buffered bytes, got %d", b.Bytes())
+		}
+
+		var results []sizedString
+		for data := range b.Chan() {
+			results = append(results, data)
+		}
+		if fmt.Sprint(results) != "[aa bb]" || b.Rejected() != 2 {
+			t.Fatalf("expected [aa bb] and 2 rejected, got %v, %d", results, b.Rejected())
+		}
+		if err := b.TrySend("ee"); err != ErrClosedPipe {
+			t.Fatalf("expected ErrClosedPipe, got %v", err)
+		}
+	})
+
+	t.Run("RejectContinues", func(t *testing.T) {
+		in := make(chan sizedString)
+		b := Elastic(ElasticConfig{MaxBytes: 5, Reject: true}, in)
+
+		in <- "aa"
+		in <- "bb"
+		in <- "cc"
+		for b.Rejected() < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		if err := b.TrySend("dd"); err != ErrBufferFull {
+			t.Fatalf("expected ErrBufferFull, got %v", err)
+		}
+
+		if data := <-b.Chan(); data != "aa" {
+			t.Fatalf("expected aa, got %v", data)
+		}
+		for b.Len() != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		if err := b.TrySend("ee"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		in <- "ff"
+		close(in)
+
+		var results []sizedString
+		for data := range b.Chan() {
+			results = append(results, data)
+		}
+		if fmt.Sprint(results) != "[bb ee]" || b.Rejected() != 2 {
+			t.Fatalf("expected [bb ee] and 2 rejected, got %v, %d", results, b.Rejected())
+		}
+	})
+}
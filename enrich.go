@@ -0,0 +1,280 @@
+package pipe
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by [Lookup.Get] when the batch loader hasn't returned the key.
+var ErrNotFound = errors.New("pipe: key not found")
+
+// defaultBatchSize is the batch size of [Lookup] if it's not configured.
+const defaultBatchSize = 100
+
+// LookupConfig configures [Lookup].
+type LookupConfig[K comparable, V any] struct {
+	// Load loads the value of the key. Either Load or LoadBatch must be set.
+	Load func(key K) (V, error)
+	// LoadBatch loads values of several keys at once. Keys missing in the result get [ErrNotFound].
+	LoadBatch func(keys []K) (map[K]V, error)
+	// BatchSize is the maximum number of keys of one LoadBatch call. By default it's 100.
+	BatchSize int
+	// BatchWait is the time to wait for more keys before LoadBatch is called.
+	BatchWait time.Duration
+	// Size is the maximum number of cached values. If it's exceeded then the least recently used value
+	// is evicted. If it's 0 then there is no limit.
+	Size int
+	// TTL is the time the value is cached. If it's 0 then the value never expires.
+	TTL time.Duration
+	// RefreshAfter is the age of the value after which it's reloaded in the background on access.
+	// The old value is used until the new one is loaded. If it's 0 then values are not refreshed.
+	RefreshAfter time.Duration
+	// Clock is the source of time. By default the [SystemClock] is used.
+	Clock Clock
+}
+
+// lookupEntry is the cached value.
+type lookupEntry[K comparable, V any] struct {
+	value      V
+	loadedAt   time.Time
+	element    *list.Element
+	refreshing bool
+}
+
+// lookupCall is the loading of the key which can be waited by several callers.
+type lookupCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Lookup is a cache of reference values, which loads missing values by the loader.
+// Concurrent loads of the same key are coalesced into one. It's safe for concurrent use.
+type Lookup[K comparable, V any] struct {
+	config  LookupConfig[K, V]
+	clock   Clock
+	mu      sync.Mutex
+	entries map[K]*lookupEntry[K, V]
+	recent  *list.List
+	calls   map[K]*lookupCall[V]
+	pending []K
+}
+
+// NewLookup creates a new lookup cache.
+func NewLookup[K comparable, V any](config LookupConfig[K, V]) *Lookup[K, V] {
+	if config.Load == nil && config.LoadBatch == nil {
+		panic("lookup loader must be set")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	return &Lookup[K, V]{
+		config:  config,
+		clock:   clockOrSystem(config.Clock),
+		entries: map[K]*lookupEntry[K, V]{},
+		recent:  list.New(),
+		calls:   map[K]*lookupCall[V]{},
+	}
+}
+
+// Get returns the value of the key from the cache or loads it.
+func (l *Lookup[K, V]) Get(key K) (V, error) {
+	l.mu.Lock()
+	if entry, ok := l.entries[key]; ok {
+		age := l.clock.Now().Sub(entry.loadedAt)
+		if l.config.TTL <= 0 || age < l.config.TTL {
+			l.recent.MoveToFront(entry.element)
+			if l.config.RefreshAfter > 0 && age >= l.config.RefreshAfter && !entry.refreshing {
+				entry.refreshing = true
+				l.load(key)
+			}
+			value := entry.value
+			l.mu.Unlock()
+			return value, nil
+		}
+	}
+
+	call, ok := l.calls[key]
+	if !ok {
+		call = l.load(key)
+	}
+	l.mu.Unlock()
+
+	<-call.done
+	return call.value, call.err
+}
+
+// Len returns the number of cached values.
+func (l *Lookup[K, V]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.entries)
+}
+
+// load starts loading of the key. Must be called under the lock.
+func (l *Lookup[K, V]) load(key K) *lookupCall[V] {
+	if call, ok := l.calls[key]; ok {
+		return call
+	}
+	call := &lookupCall[V]{done: make(chan struct{})}
+	l.calls[key] = call
+
+	if l.config.LoadBatch == nil {
+		go func() {
+			value, err := l.config.Load(key)
+			l.finish(key, value, err)
+		}()
+		return call
+	}
+
+	l.pending = append(l.pending, key)
+	if len(l.pending) >= l.config.BatchSize {
+		keys := l.pending
+		l.pending = nil
+		go l.loadBatch(keys)
+	} else if len(l.pending) == 1 {
+		go func() {
+			<-l.clock.After(l.config.BatchWait)
+			l.mu.Lock()
+			keys := l.pending
+			l.pending = nil
+			l.mu.Unlock()
+			if len(keys) > 0 {
+				l.loadBatch(keys)
+			}
+		}()
+	}
+	return call
+}
+
+// loadBatch loads the keys by the batch loader.
+func (l *Lookup[K, V]) loadBatch(keys []K) {
+	values, err := l.config.LoadBatch(keys)
+	for _, key := range keys {
+		if err != nil {
+			var zero V
+			l.finish(key, zero, err)
+		} else if value, ok := values[key]; ok {
+			l.finish(key, value, nil)
+		} else {
+			var zero V
+			l.finish(key, zero, ErrNotFound)
+		}
+	}
+}
+
+// finish caches the loaded value and releases callers waiting for it.
+// Errors are not cached, the old value is kept if the refresh is failed.
+func (l *Lookup[K, V]) finish(key K, value V, err error) {
+	l.mu.Lock()
+	call := l.calls[key]
+	delete(l.calls, key)
+
+	entry, ok := l.entries[key]
+	if ok {
+		entry.refreshing = false
+	}
+	if err == nil {
+		if !ok {
+			entry = &lookupEntry[K, V]{}
+			entry.element = l.recent.PushFront(key)
+			l.entries[key] = entry
+		} else {
+			l.recent.MoveToFront(entry.element)
+		}
+		entry.value = value
+		entry.loadedAt = l.clock.Now()
+
+		if l.config.Size > 0 && len(l.entries) > l.config.Size {
+			oldest := l.recent.Back()
+			l.recent.Remove(oldest)
+			delete(l.entries, oldest.Value.(K))
+		}
+	}
+	l.mu.Unlock()
+
+	call.value, call.err = value, err
+	close(call.done)
+}
+
+// Enrich takes message, gets the value of its key from the lookup, and combines them by merge function.
+// The lookup error is passed to the merge function as is.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Order, 4) with random values [{1 alice}, {2 bob}, {3 alice}]
+//
+//	users := NewLookup(LookupConfig[string, User]{
+//	    Load: loadUser,
+//	    Size: 1000,
+//	    TTL:  time.Hour,
+//	})
+//
+//	output := Enrich(users, func(order Order) string {
+//	    return order.User
+//	}, func(order Order, user User, err error) string {
+//	    return fmt.Sprintf("%d: %s", order.ID, user.Name)
+//	}, input)
+//
+//	// loadUser calls: alice, bob
+//	// output: ["2: Bob", "1: Alice", "3: Alice"]
+func Enrich[T any, K comparable, V any, Out any](lookup *Lookup[K, V], key func(T) K, merge func(T, V, error) Out, in <-chan T) <-chan Out {
+	return Map(enricher(lookup, key, merge), in)
+}
+
+// EnrichSync takes message, gets the value of its key from the lookup, and combines them by merge function.
+// The lookup error is passed to the merge function as is.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [Enrich]
+//
+//	// output: ["1: Alice", "2: Bob", "3: Alice"]
+func EnrichSync[T any, K comparable, V any, Out any](lookup *Lookup[K, V], key func(T) K, merge func(T, V, error) Out, in <-chan T) <-chan Out {
+	return MapSync(enricher(lookup, key, merge), in)
+}
+
+// EnrichSequential takes message, gets the value of its key from the lookup, and combines them by merge function.
+// The lookup error is passed to the merge function as is.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [Enrich]
+//
+//	// output: ["1: Alice", "2: Bob", "3: Alice"]
+func EnrichSequential[T any, K comparable, V any, Out any](lookup *Lookup[K, V], key func(T) K, merge func(T, V, error) Out, in <-chan T) <-chan Out {
+	return MapSequential(enricher(lookup, key, merge), in)
+}
+
+func enricher[T any, K comparable, V any, Out any](lookup *Lookup[K, V], key func(T) K, merge func(T, V, error) Out) func(T) Out {
+	return func(data T) Out {
+		value, err := lookup.Get(key(data))
+		return merge(data, value, err)
+	}
+}
@@ -0,0 +1,138 @@
+package pipe
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestLookup(t *testing.T) {
+	t.Run("Coalesce", func(t *testing.T) {
+		var calls int32
+		release := make(chan struct{})
+		lookup := NewLookup(LookupConfig[string, int]{
+			Load: func(key string) (int, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return len(key), nil
+			},
+		})
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				if value, err := lookup.Get("abc"); err != nil || value != 3 {
+					t.Errorf("expected 3, got %d, %v", value, err)
+				}
+				wg.Done()
+			}()
+		}
+		close(release)
+		wg.Wait()
+
+		if calls != 1 {
+			t.Fatalf("expected 1 load, got %d", calls)
+		}
+	})
+
+	t.Run("Expiration", func(t *testing.T) {
+		clock := test.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		var calls int32
+		lookup := NewLookup(LookupConfig[string, int32]{
+			Load: func(key string) (int32, error) {
+				return atomic.AddInt32(&calls, 1), nil
+			},
+			TTL:          time.Hour,
+			RefreshAfter: time.Minute,
+			Clock:        clock,
+		})
+
+		get := func() int32 {
+			value, err := lookup.Get("a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return value
+		}
+
+		if value := get(); value != 1 {
+			t.Fatalf("expected 1, got %d", value)
+		}
+		clock.Advance(time.Minute)
+		if value := get(); value != 1 {
+			t.Fatalf("expected stale 1, got %d", value)
+		}
+		for get() != 2 {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(time.Hour)
+		if value := get(); value != 3 {
+			t.Fatalf("expected reloaded 3, got %d", value)
+		}
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		batches := make(chan []string, 4)
+		lookup := NewLookup(LookupConfig[string, int]{
+			LoadBatch: func(keys []string) (map[string]int, error) {
+				batches <- keys
+				return map[string]int{"a": 1}, nil
+			},
+			BatchSize: 2,
+			BatchWait: time.Hour,
+		})
+
+		errs := make(chan error, 2)
+		for _, key := range []string{"a", "b"} {
+			key := key
+			go func() {
+				_, err := lookup.Get(key)
+				errs <- err
+			}()
+		}
+
+		if batch := <-batches; len(batch) != 2 {
+			t.Fatalf("expected batch of 2 keys, got %v", batch)
+		}
+		if err1, err2 := <-errs, <-errs; (err1 == nil) == (err2 == nil) || (err1 != ErrNotFound && err2 != ErrNotFound) {
+			t.Fatalf("expected one ErrNotFound, got %v, %v", err1, err2)
+		}
+	})
+}
+
+func TestEnrich(t *testing.T) {
+	lookup := NewLookup(LookupConfig[int, string]{
+		Load: func(key int) (string, error) {
+			return fmt.Sprint(key), nil
+		},
+		Size: 2,
+	})
+
+	test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+		pipe := test.Generator(0, 64, 16)
+
+		pipe = EnrichSync(lookup, func(val int) int {
+			return val % 4
+		}, func(val int, key string, err error) int {
+			if err != nil || key != fmt.Sprint(val%4) {
+				return -1
+			}
+			return val
+		}, pipe)
+
+		pipe, epipe = test.AssertBool("validation", pipe, epipe, func(data int) bool { return data >= 0 })
+		pipe, epipe = test.AssertOrderAsc("ordering", pipe, epipe)
+		pipe, epipe = test.AssertCount("count", pipe, epipe, 64)
+
+		return []<-chan int{pipe}, epipe
+	})
+
+	if lookup.Len() > 2 {
+		t.Fatalf("expected at most 2 cached values, got %d", lookup.Len())
+	}
+}
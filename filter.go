@@ -73,43 +73,14 @@ func Filter[T any](filter func(T) bool, in <-chan T) <-chan T {
 //	// output: [2 4]
 func FilterSync[T any](filter func(T) bool, in <-chan T) <-chan T {
 	out := make(chan T, cap(in))
-	queue := make(chan func() <-chan T, cap(in))
-	wg := sync.WaitGroup{}
-
-	go func() {
-		for {
-			if in, ok := <-in; ok {
-				wg.Add(1)
-				queue <- func() <-chan T {
-					out := make(chan T)
-					go func() {
-						if filter(in) {
-							out <- in
-						}
-						close(out)
-						wg.Done()
-					}()
-					return out
-				}
-			} else {
-				wg.Wait()
-				close(queue)
-				break
-			}
-		}
-	}()
 
 	go func() {
-		for {
-			if handler, ok := <-queue; ok {
-				if data, ok := <-handler(); ok {
-					out <- data
-				}
-			} else {
-				close(out)
-				break
-			}
-		}
+		syncProcess(in, func(in T) (T, bool) {
+			return in, filter(in)
+		}, func(data T) {
+			out <- data
+		})
+		close(out)
 	}()
 
 	return out
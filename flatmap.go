@@ -0,0 +1,281 @@
+package pipe
+
+import (
+	"context"
+	"sync"
+)
+
+// FlatMap takes message and converts it into several messages of another type by map function.
+// All messages of the result are sent one after another.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan string, 4) with random values ["a b", "c", "d e"]
+//
+//	output := FlatMap(strings.Fields, input)
+//
+//	// output: ["c", "a", "b", "d", "e"]
+func FlatMap[Tin, Tout any](mapper func(Tin) []Tout, in <-chan Tin) <-chan Tout {
+	return flatten(cap(in), Map(mapper, in))
+}
+
+// FlatMapSync takes message and converts it into several messages of another type by map function.
+// All messages of the result are sent one after another.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan string, 4) with values ["a b", "c", "d e"]
+//
+//	output := FlatMapSync(strings.Fields, input)
+//
+//	// output: ["a", "b", "c", "d", "e"]
+func FlatMapSync[Tin, Tout any](mapper func(Tin) []Tout, in <-chan Tin) <-chan Tout {
+	return flatten(cap(in), MapSync(mapper, in))
+}
+
+// FlatMapSequential takes message and converts it into several messages of another type by map function.
+// All messages of the result are sent one after another.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan string, 4) with values ["a b", "c", "d e"]
+//
+//	output := FlatMapSequential(strings.Fields, input)
+//
+//	// output: ["a", "b", "c", "d", "e"]
+func FlatMapSequential[Tin, Tout any](mapper func(Tin) []Tout, in <-chan Tin) <-chan Tout {
+	return flatten(cap(in), MapSequential(mapper, in))
+}
+
+// MergeMap takes message and converts it into the inner channel by map function. Messages of inner
+// channels are sent to the output channel as soon as they are received, so the order is not kept.
+// No more than the given number of inner channels are read at the same time, the next message of
+// the input channel waits until one of them is closed.
+// If input channel and all inner channels are closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+// Panics if the concurrency is less than 1.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan string, 4) with values ["a.txt", "b.txt"]
+//
+//	output := MergeMap(2, func(name string) <-chan string {
+//	    return ReadLines(name)
+//	}, input)
+//
+//	// output: ["a1", "b1", "a2", "b2", "b3"]
+func MergeMap[Tin, Tout any](concurrency int, mapper func(Tin) <-chan Tout, in <-chan Tin) <-chan Tout {
+	if concurrency < 1 {
+		panic("concurrency must be positive")
+	}
+	out := make(chan Tout, cap(in))
+	slots := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func() {
+					inner := mapper(in)
+					for {
+						if data, ok := <-inner; ok {
+							out <- data
+						} else {
+							break
+						}
+					}
+					<-slots
+					wg.Done()
+				}()
+			} else {
+				wg.Wait()
+				close(out)
+				break
+			}
+		}
+	}()
+
+	return out
+}
+
+// ConcatMap takes message and converts it into the inner channel by map function. Inner channels
+// are read one after another in the order of the input messages, so messages of the next inner channel
+// are sent only after the previous inner channel is closed. The map function is called as soon as
+// the message is received, so producers of the next inner channels can start ahead, no more
+// than the input channel capacity.
+// If input channel and all inner channels are closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan string, 4) with values ["a.txt", "b.txt"]
+//
+//	output := ConcatMap(func(name string) <-chan string {
+//	    return ReadLines(name)
+//	}, input)
+//
+//	// output: ["a1", "a2", "b1", "b2", "b3"]
+func ConcatMap[Tin, Tout any](mapper func(Tin) <-chan Tout, in <-chan Tin) <-chan Tout {
+	out := make(chan Tout, cap(in))
+	queue := make(chan (<-chan Tout), cap(in))
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				queue <- mapper(in)
+			} else {
+				close(queue)
+				break
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			if inner, ok := <-queue; ok {
+				for {
+					if data, ok := <-inner; ok {
+						out <- data
+					} else {
+						break
+					}
+				}
+			} else {
+				close(out)
+				break
+			}
+		}
+	}()
+
+	return out
+}
+
+// SwitchMap takes message and converts it into the inner channel by map function. Only the latest inner
+// channel is read: when a new message is received, the context of the previous inner channel is canceled,
+// its messages are dropped and it's read to the end in the background.
+// If input channel and the latest inner channel are closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan string) with values ["go", (before all results are read) "golang"]
+//
+//	output := SwitchMap(func(ctx context.Context, query string) <-chan Result {
+//	    return Search(ctx, query)
+//	}, input)
+//
+//	// output: [{go 1}, {golang 1}, {golang 2}, {golang 3}]
+func SwitchMap[Tin, Tout any](mapper func(context.Context, Tin) <-chan Tout, in <-chan Tin) <-chan Tout {
+	out := make(chan Tout, cap(in))
+
+	go func() {
+		var inner <-chan Tout
+		cancel := func() {}
+		var pending Tout
+		hasPending := false
+
+		for in != nil || inner != nil || hasPending {
+			var output chan Tout
+			var input <-chan Tout
+			if hasPending {
+				output = out
+			} else {
+				input = inner
+			}
+
+			select {
+			case data, ok := <-in:
+				if !ok {
+					in = nil
+					break
+				}
+				cancel()
+				if inner != nil {
+					go drain(inner)
+				}
+				var ctx context.Context
+				ctx, cancel = context.WithCancel(context.Background())
+				inner = mapper(ctx, data)
+				hasPending = false
+			case data, ok := <-input:
+				if !ok {
+					cancel()
+					inner = nil
+					break
+				}
+				pending = data
+				hasPending = true
+			case output <- pending:
+				hasPending = false
+			}
+		}
+		cancel()
+		close(out)
+	}()
+
+	return out
+}
+
+// flatten sends all messages of every slice of the input channel to the output channel.
+func flatten[T any](capacity int, in <-chan []T) <-chan T {
+	out := make(chan T, capacity)
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				for i := range in {
+					out <- in[i]
+				}
+			} else {
+				close(out)
+				break
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,161 @@
+package pipe
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestFlatMap(t *testing.T) {
+	twice := func(val int) []int {
+		return []int{val, val}
+	}
+
+	t.Run("Parallel", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := FlatMap(twice, test.Generator(0, 64, 16))
+
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 128)
+
+			return []<-chan int{pipe}, epipe
+		})
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := FlatMapSync(twice, test.Generator(0, 64, 16))
+
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 128)
+
+			return []<-chan int{pipe}, epipe
+		})
+
+		var got []int
+		for v := range FlatMapSync(twice, filled(4, 1, 2, 3)) {
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, []int{1, 1, 2, 2, 3, 3}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+
+	t.Run("Sequential", func(t *testing.T) {
+		var got []int
+		for v := range FlatMapSequential(twice, filled(4, 1, 2, 3)) {
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, []int{1, 1, 2, 2, 3, 3}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+}
+
+// produce returns a channel with values from*10 to from*10+n-1 which are sent slowly.
+func produce(from, n int) <-chan int {
+	out := make(chan int)
+	go func() {
+		for i := 0; i < n; i++ {
+			time.Sleep(time.Millisecond)
+			out <- from*10 + i
+		}
+		close(out)
+	}()
+	return out
+}
+
+func TestMergeMap(t *testing.T) {
+	var running, peak int64
+	out := MergeMap(3, func(val int) <-chan int {
+		n := atomic.AddInt64(&running, 1)
+		for {
+			p := atomic.LoadInt64(&peak)
+			if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+				break
+			}
+		}
+		inner := make(chan int)
+		go func() {
+			for v := range produce(val, 4) {
+				inner <- v
+			}
+			atomic.AddInt64(&running, -1)
+			close(inner)
+		}()
+		return inner
+	}, test.Generator(0, 16, 4))
+
+	count := 0
+	for range out {
+		count++
+	}
+	if count != 64 {
+		t.Errorf("expected 64 messages, got %d", count)
+	}
+	if peak > 3 {
+		t.Errorf("expected at most 3 inner channels at the same time, got %d", peak)
+	}
+}
+
+func TestConcatMap(t *testing.T) {
+	out := ConcatMap(func(val int) <-chan int {
+		return produce(val, 3)
+	}, filled(4, 3, 1, 2))
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	if want := []int{30, 31, 32, 10, 11, 12, 20, 21, 22}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSwitchMap(t *testing.T) {
+	in := make(chan int)
+	canceled := make(chan int, 2)
+	out := SwitchMap(func(ctx context.Context, val int) <-chan int {
+		inner := make(chan int)
+		go func() {
+			defer close(inner)
+			for i := 0; ; i++ {
+				if val == 2 && i == 3 {
+					return
+				}
+				select {
+				case inner <- val*10 + i:
+				case <-ctx.Done():
+					canceled <- val
+					return
+				}
+			}
+		}()
+		return inner
+	}, in)
+
+	in <- 1
+	if v := <-out; v != 10 {
+		t.Fatalf("expected 10, got %d", v)
+	}
+	in <- 2
+	select {
+	case v := <-canceled:
+		if v != 1 {
+			t.Fatalf("expected canceled inner channel of 1, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("previous inner channel isn't canceled")
+	}
+	close(in)
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	if want := []int{20, 21, 22}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,137 @@
+package pipe
+
+import (
+	"container/list"
+	"time"
+)
+
+// Grouped is the substream of messages with the same key created by [GroupBy].
+type Grouped[K comparable, T any] struct {
+	Key    K
+	Values <-chan T
+}
+
+// GroupBy takes message and forwards it to the substream of its key. When the key is seen
+// for the first time, a new substream is opened and sent to the output channel.
+// All substreams must be read, a blocked substream blocks the others.
+// If input channel is closed then all substreams and the output channel are closed.
+// Creates new channels with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Event, 4) with values [{a 1}, {b 2}, {a 3}]
+//
+//	output := GroupBy(func(e Event) string {
+//	    return e.User
+//	}, input)
+//
+//	// output: [{a [{a 1}, {a 3}]}, {b [{b 2}]}]
+func GroupBy[T any, K comparable](key func(T) K, in <-chan T) <-chan Grouped[K, T] {
+	return groupBy(nil, 0, key, in)
+}
+
+// GroupByConfig configures [GroupByIdle].
+type GroupByConfig struct {
+	// Idle is the timeout after the last message of the key when its substream is closed.
+	// If it's 0 then substreams are closed only when input channel is closed.
+	Idle time.Duration
+	// Clock is the source of time. By default the [SystemClock] is used.
+	Clock Clock
+}
+
+// GroupByIdle is the same as [GroupBy], but the substream is closed when no messages with its key
+// are received during the idle timeout. The next message with the key opens a new substream.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Event, 4) with values [{a 1}, {a 2}, (1 minute later) {a 3}]
+//
+//	output := GroupByIdle(GroupByConfig{Idle: 30 * time.Second}, func(e Event) string {
+//	    return e.User
+//	}, input)
+//
+//	// output: [{a [{a 1}, {a 2}]}, {a [{a 3}]}]
+func GroupByIdle[T any, K comparable](config GroupByConfig, key func(T) K, in <-chan T) <-chan Grouped[K, T] {
+	return groupBy(clockOrSystem(config.Clock), config.Idle, key, in)
+}
+
+type groupState[K comparable, T any] struct {
+	key  K
+	ch   chan T
+	last time.Time
+}
+
+// groupBy implements [GroupBy] and [GroupByIdle], substreams are closed after idle timeout if it's positive.
+// Substreams are kept in the list ordered by the last message, so the first one expires first.
+func groupBy[T any, K comparable](clock Clock, idle time.Duration, key func(T) K, in <-chan T) <-chan Grouped[K, T] {
+	out := make(chan Grouped[K, T], cap(in))
+
+	go func() {
+		groups := map[K]*list.Element{}
+		recent := list.New()
+		var timer <-chan time.Time
+		var armed time.Time
+
+		for {
+			if idle > 0 && recent.Len() > 0 {
+				deadline := recent.Front().Value.(*groupState[K, T]).last.Add(idle)
+				if timer == nil || !armed.Equal(deadline) {
+					timer = clock.After(deadline.Sub(clock.Now()))
+					armed = deadline
+				}
+			}
+
+			select {
+			case data, ok := <-in:
+				if !ok {
+					for element := recent.Front(); element != nil; element = element.Next() {
+						close(element.Value.(*groupState[K, T]).ch)
+					}
+					close(out)
+					return
+				}
+				k := key(data)
+				element, ok := groups[k]
+				if ok {
+					recent.MoveToBack(element)
+				} else {
+					group := &groupState[K, T]{key: k, ch: make(chan T, cap(in))}
+					element = recent.PushBack(group)
+					groups[k] = element
+					out <- Grouped[K, T]{Key: k, Values: group.ch}
+				}
+				group := element.Value.(*groupState[K, T])
+				if idle > 0 {
+					group.last = clock.Now()
+				}
+				group.ch <- data
+			case <-timer:
+				timer = nil
+				now := clock.Now()
+				for element := recent.Front(); element != nil; element = recent.Front() {
+					group := element.Value.(*groupState[K, T])
+					if now.Sub(group.last) < idle {
+						break
+					}
+					recent.Remove(element)
+					delete(groups, group.key)
+					close(group.ch)
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,97 @@
+package pipe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestGroupBy(t *testing.T) {
+	type event struct {
+		user  string
+		value int
+	}
+	user := func(e event) string {
+		return e.user
+	}
+
+	t.Run("Keys", func(t *testing.T) {
+		in := make(chan event, 8)
+		for i, name := range []string{"a", "b", "a", "c", "b", "a"} {
+			in <- event{name, i}
+		}
+		close(in)
+
+		got := map[string][]int{}
+		var keys []string
+		for group := range GroupBy(user, in) {
+			keys = append(keys, group.Key)
+			for e := range group.Values {
+				got[group.Key] = append(got[group.Key], e.value)
+			}
+		}
+		if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+			t.Errorf("unexpected keys %v", keys)
+		}
+		want := map[string][]int{"a": {0, 2, 5}, "b": {1, 4}, "c": {3}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		clock := test.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		in := make(chan event)
+		out := GroupByIdle(GroupByConfig{Idle: 10 * time.Second, Clock: clock}, user, in)
+
+		// send sends the event and returns its substream. A message of a new key doesn't change
+		// the first expiration, so it makes sure the stage has armed the timer.
+		send := func(e event, group <-chan event) <-chan event {
+			in <- e
+			if group == nil {
+				g := <-out
+				if g.Key != e.user {
+					t.Fatalf("expected group %s, got %s", e.user, g.Key)
+				}
+				group = g.Values
+			}
+			if got := <-group; got != e {
+				t.Fatalf("expected %v, got %v", e, got)
+			}
+			return group
+		}
+		closed := func(group <-chan event) bool {
+			select {
+			case _, ok := <-group:
+				return !ok
+			case <-time.After(time.Second):
+				return false
+			}
+		}
+
+		a := send(event{"a", 1}, nil)
+		b := send(event{"b", 1}, nil)
+		clock.Advance(6 * time.Second)
+		send(event{"b", 2}, b)
+		clock.Advance(4 * time.Second)
+		if !closed(a) {
+			t.Fatal("expected idle group a to be closed")
+		}
+
+		a = send(event{"a", 2}, nil)
+		clock.Advance(6 * time.Second)
+		if !closed(b) {
+			t.Fatal("expected idle group b to be closed")
+		}
+
+		close(in)
+		if !closed(a) {
+			t.Error("expected group a to be closed with input")
+		}
+		if _, ok := <-out; ok {
+			t.Error("expected closed output")
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package pipe
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// hashSeed is the seed of key hashes, it's the same during the process lifetime.
+var hashSeed = maphash.MakeSeed()
+
+// hashKey returns the hash of the comparable key. Equal keys always have equal hashes.
+func hashKey[K comparable](key K) uint64 {
+	var h maphash.Hash
+	h.SetSeed(hashSeed)
+
+	var buf [8]byte
+	switch key := any(key).(type) {
+	case string:
+		h.WriteString(key)
+	case int:
+		binary.LittleEndian.PutUint64(buf[:], uint64(key))
+		h.Write(buf[:])
+	case int32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(key))
+		h.Write(buf[:])
+	case int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(key))
+		h.Write(buf[:])
+	case uint:
+		binary.LittleEndian.PutUint64(buf[:], uint64(key))
+		h.Write(buf[:])
+	case uint32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(key))
+		h.Write(buf[:])
+	case uint64:
+		binary.LittleEndian.PutUint64(buf[:], key)
+		h.Write(buf[:])
+	case float64:
+		if key == 0 {
+			key = 0 // -0 is equal to 0
+		}
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(key))
+		h.Write(buf[:])
+	default:
+		hashValue(&h, reflect.ValueOf(key))
+	}
+	return h.Sum64()
+}
+
+// hashValue writes the comparable value to the hash. Composite values are written field by field,
+// strings are prefixed by the length, so different values never produce the same bytes.
+func hashValue(h *maphash.Hash, v reflect.Value) {
+	var buf [8]byte
+	writeUint := func(x uint64) {
+		binary.LittleEndian.PutUint64(buf[:], x)
+		h.Write(buf[:])
+	}
+	writeFloat := func(x float64) {
+		if x == 0 {
+			x = 0 // -0 is equal to 0
+		}
+		writeUint(math.Float64bits(x))
+	}
+
+	if !v.IsValid() {
+		h.WriteByte(0)
+		return
+	}
+	h.WriteByte(byte(v.Kind()))
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.WriteByte(1)
+		} else {
+			h.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		writeFloat(real(v.Complex()))
+		writeFloat(imag(v.Complex()))
+	case reflect.String:
+		writeUint(uint64(v.Len()))
+		h.WriteString(v.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint(uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			h.WriteByte(0)
+			return
+		}
+		name := v.Elem().Type().String()
+		writeUint(uint64(len(name)))
+		h.WriteString(name)
+		hashValue(h, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(h, v.Field(i))
+		}
+	default:
+		panic("pipe: key of type " + v.Type().String() + " is not comparable")
+	}
+}
@@ -0,0 +1,52 @@
+package pipe
+
+import "container/heap"
+
+// priorityQueue is a min-heap of items ordered by less function.
+type priorityQueue[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func newPriorityQueue[T any](less func(a, b T) bool) *priorityQueue[T] {
+	return &priorityQueue[T]{less: less}
+}
+
+// push adds the item to the queue.
+func (q *priorityQueue[T]) push(item T) {
+	heap.Push(q, item)
+}
+
+// pop removes and returns the least item of the queue.
+func (q *priorityQueue[T]) pop() T {
+	return heap.Pop(q).(T)
+}
+
+// peek returns the least item of the queue without removing it.
+func (q *priorityQueue[T]) peek() T {
+	return q.items[0]
+}
+
+func (q *priorityQueue[T]) Len() int {
+	return len(q.items)
+}
+
+func (q *priorityQueue[T]) Less(i, j int) bool {
+	return q.less(q.items[i], q.items[j])
+}
+
+func (q *priorityQueue[T]) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+}
+
+func (q *priorityQueue[T]) Push(x any) {
+	q.items = append(q.items, x.(T))
+}
+
+func (q *priorityQueue[T]) Pop() any {
+	var zero T
+	item := q.items[len(q.items)-1]
+	q.items[len(q.items)-1] = zero
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
@@ -0,0 +1,228 @@
+package pipe
+
+import "sync"
+
+// HubConfig is the configuration of [Hub].
+type HubConfig[T any] struct {
+	// Replay is the number of the last messages sent to new subscribers before the live messages.
+	Replay int
+	// Policy is the slow consumer policy applied when the buffer of a subscriber is full.
+	// By default the [BlockAll] policy is used.
+	Policy SlowConsumerPolicy
+	// Topic returns the topic of the message. If it's nil then subscribers can't filter messages by topics.
+	Topic func(T) string
+}
+
+// Hub forwards messages of the input channel to the subscribers, which can subscribe and unsubscribe at any time.
+type Hub[T any] struct {
+	config HubConfig[T]
+	mu     sync.Mutex
+	subs   map[*hubSubscriber[T]]struct{}
+	replay ring[T]
+	closed bool
+}
+
+type hubSubscriber[T any] struct {
+	mu     sync.Mutex
+	ch     chan T
+	closed bool
+	done   chan struct{}
+	once   sync.Once
+	topics map[string]struct{}
+	// grow is the growing queue of the subscriber with [GrowLagging] policy, it owns the channel.
+	grow *broadcastOutput[T]
+}
+
+// NewHub takes configuration and input channel, and forwards the input messages to all subscribers
+// of the returned hub. Messages received when there are no subscribers are lost, except the replay ones.
+// If the buffer of a subscriber is full then the message is handled by the slow consumer policy,
+// with [BlockAll] the hub waits for the subscriber, but stops waiting when the subscriber unsubscribes,
+// with [GrowLagging] every subscriber has its own queue which grows without limit while it lags.
+// Messages are sent without holding the hub lock, so a stuck subscriber delays next messages
+// but never blocks [Hub.Subscribe] and [Hub.Subscribers].
+// If input channel is closed then all subscriber channels are closed.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//
+// # Usages
+//
+//	// input := make(chan Event) with values [{"a" 1}, {"b" 2}, {"a" 3}]
+//
+//	hub := NewHub(HubConfig[Event]{Replay: 1, Topic: func(e Event) string { return e.Topic }}, input)
+//	events, cancel := hub.Subscribe(16, "a")
+//	defer cancel()
+//
+//	// events: [{"a" 1}, {"a" 3}]
+func NewHub[T any](config HubConfig[T], in <-chan T) *Hub[T] {
+	h := &Hub[T]{
+		config: config,
+		subs:   map[*hubSubscriber[T]]struct{}{},
+	}
+
+	go func() {
+		for {
+			if data, ok := <-in; ok {
+				h.publish(data)
+			} else {
+				h.mu.Lock()
+				h.closed = true
+				subs := h.subs
+				h.subs = nil
+				h.mu.Unlock()
+				for sub := range subs {
+					sub.close()
+				}
+				break
+			}
+		}
+	}()
+
+	return h
+}
+
+// Subscribe returns a new channel which receives the replay messages and then all new messages of the hub.
+// If topics are given then only messages with these topics are received.
+// The channel has the given buffer and room for the replay messages.
+// The returned function unsubscribes and closes the channel, it can be called several times.
+// With [GrowLagging] the channel is closed by the queue goroutine, so it can be closed a bit later.
+// If the hub is already closed then the channel receives the replay messages and is closed.
+func (h *Hub[T]) Subscribe(buffer int, topics ...string) (<-chan T, func()) {
+	sub := &hubSubscriber[T]{
+		done: make(chan struct{}),
+	}
+	if len(topics) > 0 {
+		sub.topics = make(map[string]struct{}, len(topics))
+		for _, topic := range topics {
+			sub.topics[topic] = struct{}{}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub.ch = make(chan T, buffer+h.replay.len())
+	for i := 0; i < h.replay.len(); i++ {
+		if data := h.replay.at(i); sub.accepts(h.config.Topic, data) {
+			sub.ch <- data
+		}
+	}
+	if h.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	if h.config.Policy == GrowLagging {
+		sub.grow = &broadcastOutput[T]{
+			out:   sub.ch,
+			ready: make(chan struct{}, 1),
+			space: make(chan struct{}, 1),
+			done:  sub.done,
+		}
+		go sub.grow.forward()
+	}
+	h.subs[sub] = struct{}{}
+
+	return sub.ch, func() {
+		sub.once.Do(func() {
+			close(sub.done)
+		})
+		h.mu.Lock()
+		delete(h.subs, sub)
+		h.mu.Unlock()
+		sub.close()
+	}
+}
+
+// Subscribers returns the number of active subscribers.
+func (h *Hub[T]) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// publish keeps the message for replay and sends it to all subscribers.
+// The lock is held only to take the subscribers, so a subscriber which joins during the sending
+// receives the message by replay or doesn't receive it at all, but never twice.
+func (h *Hub[T]) publish(data T) {
+	h.mu.Lock()
+	if h.config.Replay > 0 {
+		if h.replay.len() == h.config.Replay {
+			h.replay.shift()
+		}
+		h.replay.push(data)
+	}
+	subs := make([]*hubSubscriber[T], 0, len(h.subs))
+	for sub := range h.subs {
+		if sub.accepts(h.config.Topic, data) {
+			subs = append(subs, sub)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, sub := range subs {
+		if !sub.send(data, h.config.Policy) {
+			h.mu.Lock()
+			delete(h.subs, sub)
+			h.mu.Unlock()
+		}
+	}
+}
+
+// send sends the message by the slow consumer policy and returns false if the subscriber is disconnected.
+// With [BlockAll] the send waits under the subscriber lock, unsubscribing releases it by closing done channel first.
+func (s *hubSubscriber[T]) send(data T, policy SlowConsumerPolicy) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return true
+	}
+	if s.grow != nil {
+		s.grow.push(data, 0, GrowLagging)
+		return true
+	}
+	if policy == BlockAll {
+		select {
+		case s.ch <- data:
+		case <-s.done:
+		}
+		return true
+	}
+	select {
+	case s.ch <- data:
+	default:
+		if policy == DisconnectLagging {
+			s.closed = true
+			close(s.ch)
+			return false
+		}
+	}
+	return true
+}
+
+// close closes the subscriber channel once. With [GrowLagging] the channel is closed after the queue is sent,
+// or immediately if the subscriber unsubscribed.
+func (s *hubSubscriber[T]) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.grow != nil {
+		s.grow.close()
+	} else {
+		close(s.ch)
+	}
+}
+
+// accepts checks whether the subscriber receives the message.
+func (s *hubSubscriber[T]) accepts(topic func(T) string, data T) bool {
+	if s.topics == nil || topic == nil {
+		return true
+	}
+	_, ok := s.topics[topic(data)]
+	return ok
+}
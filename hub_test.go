@@ -0,0 +1,229 @@
+package pipe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHub(t *testing.T) {
+	topic := func(v string) string {
+		return v[:1]
+	}
+
+	read := func(t *testing.T, ch <-chan string, n int) []string {
+		var got []string
+		for i := 0; i < n; i++ {
+			select {
+			case v := <-ch:
+				got = append(got, v)
+			case <-time.After(time.Second):
+				t.Fatalf("expected %d messages, got %v", n, got)
+			}
+		}
+		return got
+	}
+
+	readAll := func(t *testing.T, ch <-chan string) []string {
+		var got []string
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					return got
+				}
+				got = append(got, v)
+			case <-time.After(time.Second):
+				t.Fatalf("channel isn't closed, got %v", got)
+			}
+		}
+	}
+
+	t.Run("TopicsAndReplay", func(t *testing.T) {
+		in := make(chan string)
+		hub := NewHub(HubConfig[string]{Replay: 2, Topic: topic}, in)
+
+		all, cancel := hub.Subscribe(4)
+		defer cancel()
+		for _, v := range []string{"a1", "b2", "a3"} {
+			in <- v
+		}
+		if got := read(t, all, 3); !reflect.DeepEqual(got, []string{"a1", "b2", "a3"}) {
+			t.Errorf("unexpected messages %v", got)
+		}
+
+		onlyA, _ := hub.Subscribe(4, "a")
+		if hub.Subscribers() != 2 {
+			t.Errorf("expected 2 subscribers, got %d", hub.Subscribers())
+		}
+		in <- "a4"
+		in <- "b5"
+		close(in)
+
+		if got := readAll(t, onlyA); !reflect.DeepEqual(got, []string{"a3", "a4"}) {
+			t.Errorf("unexpected messages %v", got)
+		}
+		if got := readAll(t, all); !reflect.DeepEqual(got, []string{"a4", "b5"}) {
+			t.Errorf("unexpected messages %v", got)
+		}
+
+		late, _ := hub.Subscribe(0)
+		if got := readAll(t, late); !reflect.DeepEqual(got, []string{"a4", "b5"}) {
+			t.Errorf("unexpected replay %v", got)
+		}
+	})
+
+	t.Run("CancelBlocked", func(t *testing.T) {
+		in := make(chan string)
+		hub := NewHub(HubConfig[string]{}, in)
+		defer close(in)
+
+		slow, cancel := hub.Subscribe(0)
+		in <- "a1"
+
+		done := make(chan struct{})
+		go func() {
+			cancel()
+			cancel()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("cancel is blocked by the hub")
+		}
+		if got := readAll(t, slow); len(got) != 0 {
+			t.Errorf("unexpected messages %v", got)
+		}
+		if hub.Subscribers() != 0 {
+			t.Errorf("expected no subscribers, got %d", hub.Subscribers())
+		}
+		in <- "a2"
+	})
+
+	t.Run("StuckSubscriber", func(t *testing.T) {
+		in := make(chan string)
+		hub := NewHub(HubConfig[string]{}, in)
+
+		stuck, cancelStuck := hub.Subscribe(0)
+		in <- "a1"
+
+		done := make(chan struct{})
+		var other <-chan string
+		go func() {
+			var cancel func()
+			other, cancel = hub.Subscribe(4)
+			defer cancel()
+			if hub.Subscribers() != 2 {
+				t.Errorf("expected 2 subscribers, got %d", hub.Subscribers())
+			}
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("subscribe is blocked by the stuck subscriber")
+		}
+		if len(other) != 0 {
+			t.Errorf("unexpected messages in the new subscriber")
+		}
+
+		if got := read(t, stuck, 1); !reflect.DeepEqual(got, []string{"a1"}) {
+			t.Errorf("unexpected messages %v", got)
+		}
+		cancelStuck()
+		close(in)
+	})
+
+	t.Run("BlockAll", func(t *testing.T) {
+		in := make(chan string)
+		hub := NewHub(HubConfig[string]{Policy: BlockAll}, in)
+
+		fast, _ := hub.Subscribe(1)
+		slow, _ := hub.Subscribe(1)
+		var values []string
+		for i := 0; i < 10; i++ {
+			values = append(values, fmt.Sprint(i))
+		}
+		go func() {
+			for _, v := range values {
+				in <- v
+			}
+			close(in)
+		}()
+
+		results := make(chan []string)
+		go func() {
+			var got []string
+			for v := range slow {
+				got = append(got, v)
+			}
+			results <- got
+		}()
+		if got := readAll(t, fast); !reflect.DeepEqual(got, values) {
+			t.Errorf("unexpected messages %v", got)
+		}
+		if got := <-results; !reflect.DeepEqual(got, values) {
+			t.Errorf("unexpected messages %v", got)
+		}
+	})
+
+	t.Run("GrowLagging", func(t *testing.T) {
+		in := make(chan string)
+		hub := NewHub(HubConfig[string]{Policy: GrowLagging}, in)
+
+		fast, _ := hub.Subscribe(1)
+		slow, _ := hub.Subscribe(1)
+		canceled, cancel := hub.Subscribe(1)
+		var values []string
+		for i := 0; i < 10; i++ {
+			in <- fmt.Sprint(i)
+			values = append(values, fmt.Sprint(i))
+		}
+		if got := read(t, fast, 10); !reflect.DeepEqual(got, values) {
+			t.Errorf("unexpected messages %v", got)
+		}
+		cancel()
+		if got := readAll(t, canceled); len(got) > 10 {
+			t.Errorf("unexpected messages %v", got)
+		}
+		close(in)
+
+		if got := readAll(t, slow); !reflect.DeepEqual(got, values) {
+			t.Errorf("unexpected messages %v", got)
+		}
+		if got := readAll(t, fast); len(got) != 0 {
+			t.Errorf("unexpected messages %v", got)
+		}
+	})
+
+	for _, policy := range []SlowConsumerPolicy{DropLagging, DisconnectLagging} {
+		policy := policy
+		t.Run([]string{"", "DropLagging", "DisconnectLagging"}[policy], func(t *testing.T) {
+			in := make(chan string)
+			hub := NewHub(HubConfig[string]{Policy: policy}, in)
+
+			fast, cancel := hub.Subscribe(4)
+			defer cancel()
+			slow, _ := hub.Subscribe(1)
+			for _, v := range []string{"a1", "a2", "a3"} {
+				in <- v
+			}
+			read(t, fast, 3)
+
+			if policy == DisconnectLagging {
+				if got := readAll(t, slow); !reflect.DeepEqual(got, []string{"a1"}) {
+					t.Errorf("unexpected messages %v", got)
+				}
+			} else if got := read(t, slow, 1); !reflect.DeepEqual(got, []string{"a1"}) {
+				t.Errorf("unexpected messages %v", got)
+			}
+			close(in)
+			if got := strings.Join(readAll(t, slow), ","); got != "" {
+				t.Errorf("unexpected messages %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package pipe
+
+import "time"
+
+// JoinMode defines which unmatched messages are sent by [JoinByKey].
+type JoinMode int
+
+const (
+	// InnerJoin sends only matched pairs, unmatched messages are dropped.
+	InnerJoin JoinMode = iota
+	// LeftJoin sends matched pairs and unmatched messages of the left input.
+	LeftJoin
+	// OuterJoin sends matched pairs and unmatched messages of both inputs.
+	OuterJoin
+)
+
+// Pair is the result of joining messages by the key.
+// If one of the messages is unmatched then the other side has zero value and false flag.
+type Pair[L, R any] struct {
+	Left     L
+	Right    R
+	HasLeft  bool
+	HasRight bool
+}
+
+// JoinConfig configures [JoinByKey].
+type JoinConfig struct {
+	// Mode defines which unmatched messages are sent.
+	Mode JoinMode
+	// Window is the time the message waits for matches after it was received.
+	// If it's 0 then the message waits until it's evicted or the inputs are closed.
+	Window time.Duration
+	// MaxItems is the maximum number of waiting messages of every input.
+	// If it's exceeded then the oldest message is evicted. If it's 0 then there is no limit.
+	MaxItems int
+	// Clock is the source of time. By default the [SystemClock] is used.
+	Clock Clock
+}
+
+// joinEntry is the message waiting for matches.
+type joinEntry[K comparable, T any] struct {
+	key     K
+	data    T
+	at      time.Time
+	matched bool
+}
+
+// joinSide keeps waiting messages of one input in the order they were received.
+type joinSide[K comparable, T any] struct {
+	queue []*joinEntry[K, T]
+	keys  map[K][]*joinEntry[K, T]
+}
+
+func newJoinSide[K comparable, T any]() *joinSide[K, T] {
+	return &joinSide[K, T]{keys: map[K][]*joinEntry[K, T]{}}
+}
+
+func (s *joinSide[K, T]) push(entry *joinEntry[K, T]) {
+	s.queue = append(s.queue, entry)
+	s.keys[entry.key] = append(s.keys[entry.key], entry)
+}
+
+// shift removes and returns the oldest message.
+func (s *joinSide[K, T]) shift() *joinEntry[K, T] {
+	entry := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+
+	entries := s.keys[entry.key]
+	for i := range entries {
+		if entries[i] == entry {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+	if len(entries) == 0 {
+		delete(s.keys, entry.key)
+	} else {
+		s.keys[entry.key] = entries
+	}
+	return entry
+}
+
+// JoinByKey takes messages from the left and the right inputs, and joins messages with the same key
+// received within the window by combine function. Every message is matched with all waiting messages
+// of the other input with the same key, and waits for next matches until the window is passed or it's
+// evicted. Unmatched messages are sent with the zero value of the other side or dropped depending on
+// the join mode.
+// If all input channels are closed then all waiting messages are released and output channel is closed.
+// Creates a new channel with sum of capacities of input channels.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: All
+//   - Capacity: Sum
+//
+// # Usages
+//
+//	// orders := make(chan Order, 4) with values [{1}, {2}]
+//	// payments := make(chan Payment, 4) with values [{1 $10}, {3 $5}]
+//
+//	output := JoinByKey(JoinConfig{Mode: OuterJoin, Window: 10 * time.Minute}, func(order Order) int {
+//	    return order.ID
+//	}, func(payment Payment) int {
+//	    return payment.OrderID
+//	}, func(pair Pair[Order, Payment]) string {
+//	    return fmt.Sprintf("%v %v", pair.HasLeft, pair.HasRight)
+//	}, orders, payments)
+//
+//	// output: ["true true", "true false", "false true"]
+func JoinByKey[L, R any, K comparable, Out any](config JoinConfig, leftKey func(L) K, rightKey func(R) K, combine func(Pair[L, R]) Out, left <-chan L, right <-chan R) <-chan Out {
+	out := make(chan Out, cap(left)+cap(right))
+	clock := clockOrSystem(config.Clock)
+
+	go func() {
+		lefts := newJoinSide[K, L]()
+		rights := newJoinSide[K, R]()
+
+		releaseLeft := func(entry *joinEntry[K, L]) {
+			if !entry.matched && config.Mode != InnerJoin {
+				out <- combine(Pair[L, R]{Left: entry.data, HasLeft: true})
+			}
+		}
+		releaseRight := func(entry *joinEntry[K, R]) {
+			if !entry.matched && config.Mode == OuterJoin {
+				out <- combine(Pair[L, R]{Right: entry.data, HasRight: true})
+			}
+		}
+		expire := func(now time.Time) {
+			if config.Window <= 0 {
+				return
+			}
+			for len(lefts.queue) > 0 && !lefts.queue[0].at.Add(config.Window).After(now) {
+				releaseLeft(lefts.shift())
+			}
+			for len(rights.queue) > 0 && !rights.queue[0].at.Add(config.Window).After(now) {
+				releaseRight(rights.shift())
+			}
+		}
+
+		var timer <-chan time.Time
+		var armed time.Time
+		leftIn, rightIn := left, right
+		for leftIn != nil || rightIn != nil {
+			select {
+			case data, ok := <-leftIn:
+				if !ok {
+					leftIn = nil
+					break
+				}
+				now := clock.Now()
+				expire(now)
+				entry := &joinEntry[K, L]{key: leftKey(data), data: data, at: now}
+				for _, match := range rights.keys[entry.key] {
+					out <- combine(Pair[L, R]{Left: data, Right: match.data, HasLeft: true, HasRight: true})
+					match.matched = true
+					entry.matched = true
+				}
+				lefts.push(entry)
+				if config.MaxItems > 0 && len(lefts.queue) > config.MaxItems {
+					releaseLeft(lefts.shift())
+				}
+			case data, ok := <-rightIn:
+				if !ok {
+					rightIn = nil
+					break
+				}
+				now := clock.Now()
+				expire(now)
+				entry := &joinEntry[K, R]{key: rightKey(data), data: data, at: now}
+				for _, match := range lefts.keys[entry.key] {
+					out <- combine(Pair[L, R]{Left: match.data, Right: data, HasLeft: true, HasRight: true})
+					match.matched = true
+					entry.matched = true
+				}
+				rights.push(entry)
+				if config.MaxItems > 0 && len(rights.queue) > config.MaxItems {
+					releaseRight(rights.shift())
+				}
+			case <-timer:
+				timer = nil
+				expire(clock.Now())
+			}
+
+			if config.Window > 0 && (len(lefts.queue) > 0 || len(rights.queue) > 0) {
+				var next time.Time
+				if len(lefts.queue) > 0 {
+					next = lefts.queue[0].at
+				}
+				if len(rights.queue) > 0 && (len(lefts.queue) == 0 || rights.queue[0].at.Before(next)) {
+					next = rights.queue[0].at
+				}
+				if next = next.Add(config.Window); timer == nil || next.Before(armed) {
+					armed = next
+					timer = clock.After(next.Sub(clock.Now()))
+				}
+			}
+		}
+
+		for len(lefts.queue) > 0 {
+			releaseLeft(lefts.shift())
+		}
+		for len(rights.queue) > 0 {
+			releaseRight(rights.shift())
+		}
+		close(out)
+	}()
+
+	return out
+}
@@ -0,0 +1,86 @@
+package pipe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+// joinTester sends messages to the join inputs one by one and collects the output.
+type joinTester struct {
+	clock       *test.Clock
+	left, right chan int
+	out         chan []string
+}
+
+func newJoinTester(config JoinConfig) *joinTester {
+	j := &joinTester{
+		clock: test.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		left:  make(chan int),
+		right: make(chan int),
+		out:   make(chan []string),
+	}
+	config.Clock = j.clock
+	identity := func(v int) int { return v }
+	output := JoinByKey(config, identity, identity, func(pair Pair[int, int]) string {
+		switch {
+		case pair.HasLeft && pair.HasRight:
+			return fmt.Sprintf("%d=%d", pair.Left, pair.Right)
+		case pair.HasLeft:
+			return fmt.Sprintf("%d=", pair.Left)
+		default:
+			return fmt.Sprintf("=%d", pair.Right)
+		}
+	}, j.left, j.right)
+
+	go func() {
+		var results []string
+		for result := range output {
+			results = append(results, result)
+		}
+		j.out <- results
+	}()
+	return j
+}
+
+func (j *joinTester) assert(t *testing.T, expected ...string) {
+	t.Helper()
+	close(j.left)
+	close(j.right)
+	if results := <-j.out; fmt.Sprint(results) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, results)
+	}
+}
+
+func TestJoinByKey(t *testing.T) {
+	t.Run("Outer", func(t *testing.T) {
+		j := newJoinTester(JoinConfig{Mode: OuterJoin, Window: 10 * time.Minute})
+		j.left <- 1
+		j.right <- 1
+		j.left <- 2
+		j.clock.Advance(11 * time.Minute)
+		j.right <- 3
+		j.right <- 1
+		j.assert(t, "1=1", "2=", "=3", "=1")
+	})
+
+	t.Run("Left", func(t *testing.T) {
+		j := newJoinTester(JoinConfig{Mode: LeftJoin, Window: time.Minute})
+		j.left <- 1
+		j.right <- 2
+		j.right <- 1
+		j.left <- 1
+		j.assert(t, "1=1", "1=1")
+	})
+
+	t.Run("Inner", func(t *testing.T) {
+		j := newJoinTester(JoinConfig{Mode: InnerJoin, MaxItems: 1})
+		j.left <- 1
+		j.left <- 2
+		j.right <- 1
+		j.right <- 2
+		j.assert(t, "2=2")
+	})
+}
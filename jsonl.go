@@ -0,0 +1,164 @@
+package pipe
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// streamCapacity is the capacity of the pipes created by the stream decoders.
+const streamCapacity = 64
+
+// LineError describes a decoding error of the specific line of the stream.
+type LineError struct {
+	// Line is the number of the line, starting from 1.
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSONLines reads JSON Lines (NDJSON) from the reader and sends decoded values to the returned pipe.
+// Empty lines are skipped.
+// If the reader is read to the end then the pipe is closed.
+// If the line can't be decoded or the reader fails then the pipe is closed with [LineError].
+// If the pipe is closed by the consumer then decoding stops after the current line.
+//
+// # Usages
+//
+//	// r contains lines: {"id": 1}, {"id": 2}, {"id": "3"}
+//
+//	output := DecodeJSONLines[Item](r)
+//
+//	// output.Chan(): [{1} {2}]
+//	// output.Err(): "line 3: json: cannot unmarshal string into Go struct field Item.id of type int"
+func DecodeJSONLines[T any](r io.Reader) *Pipe[T] {
+	out := NewPipe[T](streamCapacity)
+
+	go func() {
+		ctx := context.Background()
+		reader := bufio.NewReader(r)
+		for line := 1; ; line++ {
+			data, err := reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				out.CloseWithError(&LineError{Line: line, Err: err})
+				return
+			}
+			if data = bytes.TrimSpace(data); len(data) > 0 {
+				var value T
+				if err := json.Unmarshal(data, &value); err != nil {
+					out.CloseWithError(&LineError{Line: line, Err: err})
+					return
+				}
+				if out.Send(ctx, value) != nil {
+					return
+				}
+			}
+			if err == io.EOF {
+				out.Close()
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// EncodeJSONLines writes every message of the input channel to the writer as a JSON line.
+// The written data is flushed every time the input channel has no pending messages.
+// If input channel is closed then the data is flushed and the returned channel receives nil.
+// If writing fails then the returned channel receives the error and the input channel is read
+// to the end in the background.
+//
+// If the writer has a Flush method (like [bufio.Writer] or [net/http.Flusher]), it's called on every flush.
+//
+// # Usages
+//
+//	// input := make(chan Item, 4) with values [{1} {2}]
+//
+//	err := <-EncodeJSONLines(w, input)
+//
+//	// w: {"id":1}\n{"id":2}\n
+func EncodeJSONLines[T any](w io.Writer, in <-chan T) <-chan error {
+	return EncodeJSONLinesBatch(0, w, in)
+}
+
+// EncodeJSONLinesBatch writes every message of the input channel to the writer as a JSON line.
+// The written data is flushed after every batch of the given size and every time the input
+// channel has no pending messages. If the size is 0 then only the last rule is used.
+// If input channel is closed then the data is flushed and the returned channel receives nil.
+// If writing fails then the returned channel receives the error and the input channel is read
+// to the end in the background.
+//
+// If the writer has a Flush method (like [bufio.Writer] or [net/http.Flusher]), it's called on every flush.
+//
+// # Usages
+//
+//	// input := make(chan Item, 1024) with values [{1} {2} ... {1000}]
+//
+//	err := <-EncodeJSONLinesBatch(100, w, input)
+//
+//	// w: 10 flushes of 100 lines
+func EncodeJSONLinesBatch[T any](size int, w io.Writer, in <-chan T) <-chan error {
+	out := make(chan error, 1)
+
+	go func() {
+		buf := bufio.NewWriter(w)
+		enc := json.NewEncoder(buf)
+		pending := 0
+
+		flush := func() error {
+			pending = 0
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+			return flushWriter(w)
+		}
+
+		var err error
+		for {
+			if data, ok := <-in; ok {
+				if err = enc.Encode(data); err != nil {
+					break
+				}
+				pending++
+				if (size > 0 && pending >= size) || len(in) == 0 {
+					if err = flush(); err != nil {
+						break
+					}
+				}
+			} else {
+				err = flush()
+				break
+			}
+		}
+
+		if err != nil {
+			go drain(in)
+		}
+		out <- err
+		close(out)
+	}()
+
+	return out
+}
+
+// flushWriter calls Flush method of the writer if it exists.
+func flushWriter(w io.Writer) error {
+	switch w := w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Flush() }:
+		w.Flush()
+	}
+	return nil
+}
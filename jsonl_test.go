@@ -0,0 +1,105 @@
+package pipe
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/msacore/pipe/test"
+)
+
+type jsonItem struct {
+	ID int `json:"id"`
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestDecodeJSONLines(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		r := strings.NewReader("{\"id\": 1}\n\n{\"id\": 2}\n{\"id\": 3}")
+		out := DecodeJSONLines[jsonItem](r)
+
+		sum := 0
+		for item := range MapSync(func(item jsonItem) int { return item.ID }, out.Chan()) {
+			sum += item
+		}
+		if sum != 6 {
+			t.Fatalf("expected sum 6, got %d", sum)
+		}
+		if out.Err() != nil {
+			t.Fatal(out.Err())
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		r := strings.NewReader("{\"id\": 1}\n{\"id\": \"2\"}\n{\"id\": 3}\n")
+		out := DecodeJSONLines[jsonItem](r)
+
+		count := 0
+		for range out.Chan() {
+			count++
+		}
+		if count != 1 {
+			t.Fatalf("expected 1 item, got %d", count)
+		}
+		var lineErr *LineError
+		if !errors.As(out.Err(), &lineErr) || lineErr.Line != 2 {
+			t.Fatalf("expected error on line 2, got %v", out.Err())
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		r := strings.NewReader(strings.Repeat("{\"id\": 1}\n", 1024))
+		out := DecodeJSONLines[jsonItem](r)
+		<-out.Chan()
+		out.Close()
+		<-Wait(out.Chan())
+	})
+}
+
+func TestEncodeJSONLines(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		in := make(chan jsonItem, 4)
+		in <- jsonItem{ID: 1}
+		in <- jsonItem{ID: 2}
+		close(in)
+
+		buf := &bytes.Buffer{}
+		if err := <-EncodeJSONLinesBatch(1, buf, in); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != "{\"id\":1}\n{\"id\":2}\n" {
+			t.Fatalf("unexpected output %q", buf.String())
+		}
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		in := Map(func(val int) jsonItem { return jsonItem{ID: val} }, test.Generator(0, 64, 16))
+		if err := <-EncodeJSONLines(buf, in); err != nil {
+			t.Fatal(err)
+		}
+
+		out := DecodeJSONLines[jsonItem](buf)
+		count := 0
+		for range out.Chan() {
+			count++
+		}
+		if count != 64 || out.Err() != nil {
+			t.Fatalf("expected 64 items, got %d, %v", count, out.Err())
+		}
+	})
+
+	t.Run("WriteError", func(t *testing.T) {
+		in := test.Generator(0, 64, 16)
+		if err := <-EncodeJSONLines(failWriter{}, in); err == nil {
+			t.Fatal("expected error")
+		}
+		<-Wait(in)
+	})
+}
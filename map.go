@@ -69,41 +69,14 @@ func Map[Tin, Tout any](mapper func(Tin) Tout, in <-chan Tin) <-chan Tout {
 //	// output: ["val: 1", "val: 2", "val: 3"]
 func MapSync[Tin, Tout any](mapper func(Tin) Tout, in <-chan Tin) <-chan Tout {
 	out := make(chan Tout, cap(in))
-	queue := make(chan func() <-chan Tout, cap(in))
-	wg := sync.WaitGroup{}
 
 	go func() {
-		for {
-			if in, ok := <-in; ok {
-				wg.Add(1)
-				queue <- func() <-chan Tout {
-					out := make(chan Tout)
-					go func() {
-						out <- mapper(in)
-						close(out)
-						wg.Done()
-					}()
-					return out
-				}
-			} else {
-				wg.Wait()
-				close(queue)
-				break
-			}
-		}
-	}()
-
-	go func() {
-		for {
-			if handler, ok := <-queue; ok {
-				if data, ok := <-handler(); ok {
-					out <- data
-				}
-			} else {
-				close(out)
-				break
-			}
-		}
+		syncProcess(in, func(in Tin) (Tout, bool) {
+			return mapper(in), true
+		}, func(data Tout) {
+			out <- data
+		})
+		close(out)
 	}()
 
 	return out
@@ -146,3 +119,73 @@ func MapSequential[Tin, Tout any](mapper func(Tin) Tout, in <-chan Tin) <-chan T
 
 	return out
 }
+
+// MapByKey takes message and converts it into another type by map function.
+// Messages with the same key are processed one after the other in the order they were received,
+// messages with different keys are processed in parallel. Each key is assigned to one of the
+// given number of shards by the key hash, every shard is processed by its own goroutine.
+// There is no guarantee that the output order of messages with different keys will be consistent.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential for the same key, Parallel for different keys
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Event, 4) with values [{a 1}, {b 1}, {a 2}, {b 2}]
+//
+//	output := MapByKey(func(event Event) string {
+//	    return event.User
+//	}, 4, func(event Event) string {
+//	    fmt.Print(event)
+//	    return fmt.Sprintf("%s: %d", event.User, event.Seq)
+//	}, input)
+//
+//	// stdout: {b 1} {a 1} {b 2} {a 2}
+//	// output: ["b: 1", "a: 1", "b: 2", "a: 2"]
+func MapByKey[Tin any, K comparable, Tout any](key func(Tin) K, shards int, mapper func(Tin) Tout, in <-chan Tin) <-chan Tout {
+	if shards < 1 {
+		panic("shards count must be positive")
+	}
+
+	out := make(chan Tout, cap(in))
+	queues := make([]chan Tin, shards)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < shards; i++ {
+		queues[i] = make(chan Tin, cap(in))
+		queue := queues[i]
+		wg.Add(1)
+		go func() {
+			for {
+				if in, ok := <-queue; ok {
+					out <- mapper(in)
+				} else {
+					wg.Done()
+					break
+				}
+			}
+		}()
+	}
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				queues[hashKey(key(in))%uint64(shards)] <- in
+			} else {
+				for i := 0; i < shards; i++ {
+					close(queues[i])
+				}
+				wg.Wait()
+				close(out)
+				break
+			}
+		}
+	}()
+
+	return out
+}
@@ -1,6 +1,7 @@
 package pipe
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/msacore/pipe/test"
@@ -50,4 +51,28 @@ func TestMap(t *testing.T) {
 			return []<-chan float32{pipe2}, epipe
 		})
 	})
+
+	t.Run("ByKey", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := test.Generator(0, 64, 16)
+
+			pipe2 := MapByKey(func(val int) int {
+				return val % 4
+			}, 3, func(val int) int {
+				return val
+			}, pipe)
+
+			last := map[int]int{}
+			pipe2, epipe = test.Assert("key ordering", pipe2, epipe, func(data int) error {
+				if prev, ok := last[data%4]; ok && prev > data {
+					return fmt.Errorf("key %d order is broken: prev %d, current %d", data%4, prev, data)
+				}
+				last[data%4] = data
+				return nil
+			})
+			pipe2, epipe = test.AssertCount("count", pipe2, epipe, 64)
+
+			return []<-chan int{pipe2}, epipe
+		})
+	})
 }
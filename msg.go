@@ -0,0 +1,215 @@
+package pipe
+
+// Metadata is a set of message attributes like trace IDs, timestamps, sequence numbers and headers.
+type Metadata map[string]any
+
+// Clone returns a copy of the metadata.
+func (m Metadata) Clone() Metadata {
+	clone := make(Metadata, len(m))
+	for key, value := range m {
+		clone[key] = value
+	}
+	return clone
+}
+
+// Msg is the message envelope which carries metadata together with the value.
+type Msg[T any] struct {
+	Value T
+	Meta  Metadata
+}
+
+// NewMsg creates a new message with the value and the metadata.
+// If the metadata is nil then an empty one is created.
+func NewMsg[T any](value T, meta Metadata) Msg[T] {
+	if meta == nil {
+		meta = Metadata{}
+	}
+	return Msg[T]{Value: value, Meta: meta}
+}
+
+// Wrap takes value and wraps it into the message with empty metadata.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3]
+//
+//	output := Wrap(input)
+//
+//	// output: [{1 map[]}, {2 map[]}, {3 map[]}]
+func Wrap[T any](in <-chan T) <-chan Msg[T] {
+	return MapSequential(func(value T) Msg[T] {
+		return NewMsg(value, nil)
+	}, in)
+}
+
+// Unwrap takes message and forwards its value without metadata.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Msg[int], 4) with values [{1 map[]}, {2 map[]}, {3 map[]}]
+//
+//	output := Unwrap(input)
+//
+//	// output: [1, 2, 3]
+func Unwrap[T any](in <-chan Msg[T]) <-chan T {
+	return MapSequential(func(msg Msg[T]) T {
+		return msg.Value
+	}, in)
+}
+
+// MapMsg takes message and converts its value into another type by map function.
+// The metadata is copied, the copy is passed to the map function, which may change it, and to the output message.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Msg[int], 4) with random values [{1 {trace: a}}, {2 {trace: b}}]
+//
+//	output := MapMsg(func(value int, meta Metadata) string {
+//	    fmt.Print(meta["trace"])
+//	    return fmt.Sprintf("val: %d", value)
+//	}, input)
+//
+//	// stdout: b a
+//	// output: [{"val: 2" {trace: b}}, {"val: 1" {trace: a}}]
+func MapMsg[Tin, Tout any](mapper func(Tin, Metadata) Tout, in <-chan Msg[Tin]) <-chan Msg[Tout] {
+	return Map(msgMapper(mapper), in)
+}
+
+// MapMsgSync takes message and converts its value into another type by map function.
+// The metadata is copied, the copy is passed to the map function, which may change it, and to the output message.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [MapMsg]
+//
+//	// stdout: b a
+//	// output: [{"val: 1" {trace: a}}, {"val: 2" {trace: b}}]
+func MapMsgSync[Tin, Tout any](mapper func(Tin, Metadata) Tout, in <-chan Msg[Tin]) <-chan Msg[Tout] {
+	return MapSync(msgMapper(mapper), in)
+}
+
+// MapMsgSequential takes message and converts its value into another type by map function.
+// The metadata is copied, the copy is passed to the map function, which may change it, and to the output message.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [MapMsg]
+//
+//	// stdout: a b
+//	// output: [{"val: 1" {trace: a}}, {"val: 2" {trace: b}}]
+func MapMsgSequential[Tin, Tout any](mapper func(Tin, Metadata) Tout, in <-chan Msg[Tin]) <-chan Msg[Tout] {
+	return MapSequential(msgMapper(mapper), in)
+}
+
+// FilterMsg takes message and forwards it if filter function return positive.
+// The metadata is passed to the filter function.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Msg[int], 4) with random values [{1 {trace: a}}, {2 {trace: b}}]
+//
+//	output := FilterMsg(func(value int, meta Metadata) bool {
+//	    return meta["trace"] == "b"
+//	}, input)
+//
+//	// output: [{2 {trace: b}}]
+func FilterMsg[T any](filter func(T, Metadata) bool, in <-chan Msg[T]) <-chan Msg[T] {
+	return Filter(msgFilter(filter), in)
+}
+
+// FilterMsgSync takes message and forwards it if filter function return positive.
+// The metadata is passed to the filter function.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [FilterMsg]
+func FilterMsgSync[T any](filter func(T, Metadata) bool, in <-chan Msg[T]) <-chan Msg[T] {
+	return FilterSync(msgFilter(filter), in)
+}
+
+// FilterMsgSequential takes message and forwards it if filter function return positive.
+// The metadata is passed to the filter function.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// See [FilterMsg]
+func FilterMsgSequential[T any](filter func(T, Metadata) bool, in <-chan Msg[T]) <-chan Msg[T] {
+	return FilterSequential(msgFilter(filter), in)
+}
+
+func msgMapper[Tin, Tout any](mapper func(Tin, Metadata) Tout) func(Msg[Tin]) Msg[Tout] {
+	return func(msg Msg[Tin]) Msg[Tout] {
+		meta := msg.Meta.Clone()
+		return Msg[Tout]{Value: mapper(msg.Value, meta), Meta: meta}
+	}
+}
+
+func msgFilter[T any](filter func(T, Metadata) bool) func(Msg[T]) bool {
+	return func(msg Msg[T]) bool {
+		return filter(msg.Value, msg.Meta)
+	}
+}
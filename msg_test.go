@@ -0,0 +1,96 @@
+package pipe
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestMsg(t *testing.T) {
+	t.Run("Map", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := MapSequential(func(val int) Msg[int] {
+				return NewMsg(val, Metadata{"seq": val})
+			}, test.Generator(0, 64, 16))
+
+			pipe2 := MapMsgSync(func(val int, meta Metadata) string {
+				return fmt.Sprint(val)
+			}, pipe)
+			pipe3 := MapMsgSequential(func(val string, meta Metadata) int {
+				if fmt.Sprint(meta["seq"]) != val {
+					return -1
+				}
+				return meta["seq"].(int)
+			}, pipe2)
+			pipe3 = MapMsg(func(val int, meta Metadata) int {
+				if meta["seq"] != val {
+					return -1
+				}
+				return val
+			}, pipe3)
+			pipe4 := Unwrap(pipe3)
+
+			pipe4, epipe = test.AssertBool("metadata", pipe4, epipe, func(data int) bool { return data >= 0 })
+			pipe4, epipe = test.AssertCount("count", pipe4, epipe, 64)
+
+			return []<-chan int{pipe4}, epipe
+		})
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := MapSequential(func(msg Msg[int]) Msg[int] {
+				msg.Meta["even"] = msg.Value%2 == 0
+				return msg
+			}, Wrap(test.Generator(0, 64, 16)))
+
+			pipe = FilterMsgSync(func(val int, meta Metadata) bool {
+				return meta["even"].(bool)
+			}, pipe)
+			pipe2 := Unwrap(FilterMsgSequential(func(val int, meta Metadata) bool {
+				return true
+			}, FilterMsg(func(val int, meta Metadata) bool {
+				return val%2 == 0
+			}, pipe)))
+
+			pipe2, epipe = test.AssertBool("validation", pipe2, epipe, func(data int) bool { return data%2 == 0 })
+			pipe2, epipe = test.AssertCount("count", pipe2, epipe, 32)
+
+			return []<-chan int{pipe2}, epipe
+		})
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		in := make(chan Msg[int], 4)
+		go func() {
+			for i := 0; i < 16; i++ {
+				in <- NewMsg(i, Metadata{"stage": "source"})
+			}
+			close(in)
+		}()
+
+		branch := func(name string, in <-chan Msg[int]) <-chan Msg[int] {
+			return MapMsg(func(val int, meta Metadata) int {
+				meta["stage"] = name
+				return val
+			}, in)
+		}
+		left, right := Split2(in)
+		left, right = branch("left", left), branch("right", right)
+
+		check := func(name string, out <-chan Msg[int], done chan<- struct{}) {
+			for msg := range out {
+				if msg.Meta["stage"] != name {
+					t.Errorf("expected stage %s, got %v", name, msg.Meta["stage"])
+				}
+			}
+			done <- struct{}{}
+		}
+		done := make(chan struct{})
+		go check("left", left, done)
+		go check("right", right, done)
+		<-done
+		<-done
+	})
+}
@@ -0,0 +1,103 @@
+package pipe
+
+// Processing is the processing strategy of the operator, see the package documentation.
+type Processing int
+
+const (
+	// Parallel processes messages in separate goroutines without ordering.
+	Parallel Processing = iota
+	// Sync processes messages in separate goroutines and keeps the order.
+	Sync
+	// Sequential processes messages one after another.
+	Sequential
+)
+
+// Options are the options of operators created by With functions, like [MapWith].
+type Options struct {
+	// Processing is the processing strategy. By default it's [Parallel].
+	Processing Processing
+	// Size is the size of the output buffer. If it's 0 then the capacity of the input channel is used,
+	// but at least 1.
+	Size int
+	// Overflow is the policy applied when the output buffer is full. By default it's [Block].
+	Overflow OverflowPolicy
+}
+
+// MapWith takes message and converts it into another type by map function like [Map], [MapSync]
+// or [MapSequential] depending on the processing option. Converted messages are kept in the output
+// buffer, if it's full then the message is handled by the overflow policy, so a slow consumer
+// doesn't block the mapping unless the policy is [Block].
+// If input channel is closed then all buffered messages are sent and output channel is closed.
+//
+// # Strategies
+//
+//   - Processing: Options.Processing
+//   - Closing: Single
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5] received before the consumer is ready
+//
+//	output := MapWith(Options{Processing: Sequential, Size: 3, Overflow: DropOldest}, func(value int) string {
+//	    return fmt.Sprintf("val: %d", value)
+//	}, input)
+//
+//	// output.Chan(): ["val: 3", "val: 4", "val: 5"]
+//	// output.Dropped(): 2
+func MapWith[Tin, Tout any](options Options, mapper func(Tin) Tout, in <-chan Tin) *Buffered[Tout] {
+	var out <-chan Tout
+	switch options.Processing {
+	case Sync:
+		out = MapSync(mapper, in)
+	case Sequential:
+		out = MapSequential(mapper, in)
+	default:
+		out = Map(mapper, in)
+	}
+	return Buffer(options.size(cap(in)), options.Overflow, out)
+}
+
+// FilterWith takes message and forwards it to the output buffer if the filter function returns
+// positive like [Filter], [FilterSync] or [FilterSequential] depending on the processing option.
+// If the output buffer is full then the message is handled by the overflow policy, so a slow consumer
+// doesn't block the filtering unless the policy is [Block].
+// If input channel is closed then all buffered messages are sent and output channel is closed.
+//
+// # Strategies
+//
+//   - Processing: Options.Processing
+//   - Closing: Single
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5, 6] received before the consumer is ready
+//
+//	output := FilterWith(Options{Processing: Sync, Size: 1, Overflow: Latest}, func(value int) bool {
+//	    return value%2 == 0
+//	}, input)
+//
+//	// output.Chan(): [6]
+//	// output.Dropped(): 2
+func FilterWith[T any](options Options, filter func(T) bool, in <-chan T) *Buffered[T] {
+	var out <-chan T
+	switch options.Processing {
+	case Sync:
+		out = FilterSync(filter, in)
+	case Sequential:
+		out = FilterSequential(filter, in)
+	default:
+		out = Filter(filter, in)
+	}
+	return Buffer(options.size(cap(in)), options.Overflow, out)
+}
+
+// size returns the size of the output buffer for the input channel capacity.
+func (o Options) size(capacity int) int {
+	if o.Size > 0 {
+		return o.Size
+	}
+	if capacity > 0 {
+		return capacity
+	}
+	return 1
+}
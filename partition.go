@@ -0,0 +1,255 @@
+package pipe
+
+import "sync"
+
+// Partition takes message and forwards it to the matched output channel if the predicate returns true
+// or to the unmatched output channel otherwise. The predicate is called once per message.
+// There is no guarantee that the output order will be consistent.
+// If input channel is closed then both output channels are closed.
+// Creates new channels with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with random values [1, 2, 3, 4, 5]
+//
+//	even, odd := Partition(func(value int) bool {
+//	    return value%2 == 0
+//	}, input)
+//
+//	// even: [4, 2]
+//	// odd: [3, 1, 5]
+func Partition[T any](predicate func(T) bool, in <-chan T) (matched, unmatched <-chan T) {
+	outs := PartitionN(2, predicateBucket(predicate), in)
+	return outs[0], outs[1]
+}
+
+// PartitionSync takes message and forwards it to the matched output channel if the predicate returns true
+// or to the unmatched output channel otherwise. The predicate is called once per message.
+// Every output keeps the order of the input.
+// If input channel is closed then both output channels are closed.
+// Creates new channels with the same capacity as input.
+//
+// Be aware, if one of the output channels is blocked, then the other output channel will wait.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5]
+//
+//	even, odd := PartitionSync(func(value int) bool {
+//	    return value%2 == 0
+//	}, input)
+//
+//	// even: [2, 4]
+//	// odd: [1, 3, 5]
+func PartitionSync[T any](predicate func(T) bool, in <-chan T) (matched, unmatched <-chan T) {
+	outs := PartitionNSync(2, predicateBucket(predicate), in)
+	return outs[0], outs[1]
+}
+
+// PartitionSequential takes message and forwards it to the matched output channel if the predicate returns true
+// or to the unmatched output channel otherwise. The predicate is called once per message.
+// Every output keeps the order of the input.
+// If input channel is closed then both output channels are closed.
+// Creates new channels with the same capacity as input.
+//
+// Be aware, if one of the output channels is blocked, then the other output channel will wait.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5]
+//
+//	even, odd := PartitionSequential(func(value int) bool {
+//	    return value%2 == 0
+//	}, input)
+//
+//	// even: [2, 4]
+//	// odd: [1, 3, 5]
+func PartitionSequential[T any](predicate func(T) bool, in <-chan T) (matched, unmatched <-chan T) {
+	outs := PartitionNSequential(2, predicateBucket(predicate), in)
+	return outs[0], outs[1]
+}
+
+// PartitionN takes a number of output channels, bucket function and input channel, and forwards
+// the message to the output channel with the index returned by the bucket function.
+// Messages with the index out of range are dropped.
+// There is no guarantee that the output order will be consistent.
+// If input channel is closed then all output channels are closed.
+// Creates new channels with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Parallel
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with random values [1, 2, 3, 4, 5, 6]
+//
+//	outs := PartitionN(3, func(value int) int {
+//	    return value % 3
+//	}, input)
+//
+//	// outs[0]: [6, 3]
+//	// outs[1]: [1, 4]
+//	// outs[2]: [5, 2]
+func PartitionN[T any](n int, bucket func(T) int, in <-chan T) GroupReaders[T] {
+	outs := newPartitionGroup[T](n, cap(in))
+	wg := sync.WaitGroup{}
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				wg.Add(1)
+				go func() {
+					if i := bucket(in); i >= 0 && i < n {
+						outs[i] <- in
+					}
+					wg.Done()
+				}()
+			} else {
+				wg.Wait()
+				for i := range outs {
+					close(outs[i])
+				}
+				break
+			}
+		}
+	}()
+
+	return outs.Readers()
+}
+
+// PartitionNSync takes a number of output channels, bucket function and input channel, and forwards
+// the message to the output channel with the index returned by the bucket function.
+// Messages with the index out of range are dropped.
+// Every output keeps the order of the input.
+// If input channel is closed then all output channels are closed.
+// Creates new channels with the same capacity as input.
+//
+// Be aware, if one of the output channels is blocked, then all other output channels will wait.
+//
+// # Strategies
+//
+//   - Processing: Sync
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5, 6]
+//
+//	outs := PartitionNSync(3, func(value int) int {
+//	    return value % 3
+//	}, input)
+//
+//	// outs[0]: [3, 6]
+//	// outs[1]: [1, 4]
+//	// outs[2]: [2, 5]
+func PartitionNSync[T any](n int, bucket func(T) int, in <-chan T) GroupReaders[T] {
+	outs := newPartitionGroup[T](n, cap(in))
+
+	go func() {
+		syncProcess(in, func(in T) (partitioned[T], bool) {
+			i := bucket(in)
+			return partitioned[T]{bucket: i, data: in}, i >= 0 && i < n
+		}, func(data partitioned[T]) {
+			outs[data.bucket] <- data.data
+		})
+		for i := range outs {
+			close(outs[i])
+		}
+	}()
+
+	return outs.Readers()
+}
+
+// PartitionNSequential takes a number of output channels, bucket function and input channel, and forwards
+// the message to the output channel with the index returned by the bucket function.
+// Messages with the index out of range are dropped.
+// Every output keeps the order of the input.
+// If input channel is closed then all output channels are closed.
+// Creates new channels with the same capacity as input.
+//
+// Be aware, if one of the output channels is blocked, then all other output channels will wait.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5, 6]
+//
+//	outs := PartitionNSequential(3, func(value int) int {
+//	    return value % 3
+//	}, input)
+//
+//	// outs[0]: [3, 6]
+//	// outs[1]: [1, 4]
+//	// outs[2]: [2, 5]
+func PartitionNSequential[T any](n int, bucket func(T) int, in <-chan T) GroupReaders[T] {
+	outs := newPartitionGroup[T](n, cap(in))
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				if i := bucket(in); i >= 0 && i < n {
+					outs[i] <- in
+				}
+			} else {
+				for i := range outs {
+					close(outs[i])
+				}
+				break
+			}
+		}
+	}()
+
+	return outs.Readers()
+}
+
+// partitioned is the message with the index of its output channel.
+type partitioned[T any] struct {
+	bucket int
+	data   T
+}
+
+// newPartitionGroup creates a group of n channels with the given capacity.
+func newPartitionGroup[T any](n, capacity int) Group[T] {
+	outs := make(Group[T], n)
+	for i := range outs {
+		outs[i] = make(chan T, capacity)
+	}
+	return outs
+}
+
+// predicateBucket converts the predicate to the bucket function: 0 for matched messages, 1 for others.
+func predicateBucket[T any](predicate func(T) bool) func(T) int {
+	return func(data T) int {
+		if predicate(data) {
+			return 0
+		}
+		return 1
+	}
+}
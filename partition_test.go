@@ -0,0 +1,97 @@
+package pipe
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestPartition(t *testing.T) {
+	even := func(val int) bool {
+		return val%2 == 0
+	}
+
+	t.Run("Parallel", func(t *testing.T) {
+		var calls int64
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := test.Generator(0, 64, 16)
+
+			matched, unmatched := Partition(func(val int) bool {
+				atomic.AddInt64(&calls, 1)
+				return even(val)
+			}, pipe)
+			matched, epipe = test.AssertCount("matched count", matched, epipe, 32)
+			unmatched, epipe = test.AssertCount("unmatched count", unmatched, epipe, 32)
+
+			return []<-chan int{matched, unmatched}, epipe
+		})
+		if calls != 64 {
+			t.Errorf("expected 64 predicate calls, got %d", calls)
+		}
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := test.Generator(0, 64, 16)
+
+			matched, unmatched := PartitionSync(even, pipe)
+			matched, epipe = test.AssertCount("matched count", matched, epipe, 32)
+			matched, epipe = test.AssertOrderAsc("matched ordering", matched, epipe)
+			unmatched, epipe = test.AssertCount("unmatched count", unmatched, epipe, 32)
+			unmatched, epipe = test.AssertOrderAsc("unmatched ordering", unmatched, epipe)
+
+			return []<-chan int{matched, unmatched}, epipe
+		})
+	})
+
+	t.Run("Sequential", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := test.Generator(0, 64, 16)
+
+			matched, unmatched := PartitionSequential(even, pipe)
+			matched, epipe = test.AssertCount("matched count", matched, epipe, 32)
+			matched, epipe = test.AssertOrderAsc("matched ordering", matched, epipe)
+			unmatched, epipe = test.AssertCount("unmatched count", unmatched, epipe, 32)
+			unmatched, epipe = test.AssertOrderAsc("unmatched ordering", unmatched, epipe)
+
+			return []<-chan int{matched, unmatched}, epipe
+		})
+	})
+}
+
+func TestPartitionN(t *testing.T) {
+	// bucket returns index 0..2 for values below 48 and 3 (out of range) for the rest.
+	bucket := func(val int) int {
+		if val >= 48 {
+			return 3
+		}
+		return val % 3
+	}
+
+	variants := map[string]func(int, func(int) int, <-chan int) GroupReaders[int]{
+		"Parallel":   PartitionN[int],
+		"Sync":       PartitionNSync[int],
+		"Sequential": PartitionNSequential[int],
+	}
+	for name, partition := range variants {
+		partition := partition
+		ordered := name != "Parallel"
+		t.Run(name, func(t *testing.T) {
+			test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+				pipe := test.Generator(0, 64, 16)
+
+				pipes := partition(3, bucket, pipe)
+				for i := range pipes {
+					pipes[i], epipe = test.AssertCount(fmt.Sprintf("count %d", i), pipes[i], epipe, 16)
+					if ordered {
+						pipes[i], epipe = test.AssertOrderAsc(fmt.Sprintf("ordering %d", i), pipes[i], epipe)
+					}
+				}
+
+				return pipes, epipe
+			})
+		})
+	}
+}
@@ -9,10 +9,10 @@
 //
 //   - Parallel - Each handler is executed in its own goroutine and there is no guarantee that the output order
 //     will be consistent. Recommended for best performance.
-//   - Sync - Each handler executes in its own goroutine, but the result of the youngest goroutine waits for the oldest
-//     goroutine to finish before being passed to the output stream. To prevent memory leaks, the strategy will wait if
-//     there is more waiting data than the capacity of the output channel. Recommended if you want to get the output
-//     data in the same order as the input data.
+//   - Sync - Handlers are executed by a fixed pool of goroutines, but the result of the youngest message waits for
+//     the oldest message to finish before being passed to the output stream. To prevent memory leaks, the results wait
+//     in a ring of slots of the input channel capacity and the strategy will wait if all slots are busy. Recommended
+//     if you want to get the output data in the same order as the input data.
 //   - Sequential - Each handler is executed sequentially, one after the other. Keeps the order of the output data
 //     equal to the order of the input data. Recommended if it is necessary to exclude the race of threads between
 //     handlers.
@@ -42,3 +42,167 @@
 //   - Max - The output channels will have a capacity equal to the maximum capacity of the input channels.
 //   - Sum - The output channels will have a capacity equal to the sum of capacities of the input channels.
 package pipe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrClosedPipe is returned by [Pipe.Send] when the pipe is already closed.
+var ErrClosedPipe = errors.New("pipe: send on closed pipe")
+
+// Pipe is a typed channel which can be closed with an error, like [io.Pipe].
+// The reader receives all buffered messages and then the error the writer closed the pipe with.
+// Use [Pipe.Chan] to connect the pipe with other functions of the package and [Through] to keep
+// the error on the way to the end of the pipeline.
+//
+// # Usages
+//
+//	p := NewPipe[int](4)
+//
+//	go func() {
+//	    p.Send(ctx, 1)
+//	    p.CloseWithError(errors.New("broken source"))
+//	}()
+//
+//	v, err := p.Recv(ctx) // 1, nil
+//	v, err = p.Recv(ctx)  // 0, "broken source"
+type Pipe[T any] struct {
+	ch   chan T
+	done chan struct{}
+	once sync.Once
+	mu   sync.RWMutex
+	err  error
+}
+
+// NewPipe creates a new pipe with the given capacity.
+func NewPipe[T any](capacity int) *Pipe[T] {
+	return &Pipe[T]{
+		ch:   make(chan T, capacity),
+		done: make(chan struct{}),
+	}
+}
+
+// Send sends the message to the pipe. It blocks until the message is accepted by the pipe,
+// the pipe is closed or the context is done.
+// Returns [ErrClosedPipe] if the pipe is closed.
+func (p *Pipe[T]) Send(ctx context.Context, v T) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	select {
+	case <-p.done:
+		return ErrClosedPipe
+	default:
+	}
+
+	select {
+	case p.ch <- v:
+		return nil
+	case <-p.done:
+		return ErrClosedPipe
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Recv receives the next message from the pipe. It blocks until a message is available,
+// the pipe is closed or the context is done.
+// If the pipe is closed and there are no buffered messages, it returns the close error
+// or [io.EOF] if the pipe was closed without an error.
+func (p *Pipe[T]) Recv(ctx context.Context) (T, error) {
+	select {
+	case v, ok := <-p.ch:
+		if ok {
+			return v, nil
+		}
+		var zero T
+		if err := p.Err(); err != nil {
+			return zero, err
+		}
+		return zero, io.EOF
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Close closes the pipe. The reader receives [io.EOF] after all buffered messages.
+func (p *Pipe[T]) Close() error {
+	return p.CloseWithError(nil)
+}
+
+// CloseWithError closes the pipe. The reader receives the error after all buffered messages.
+// Only the first close has an effect, the following calls are ignored.
+func (p *Pipe[T]) CloseWithError(err error) error {
+	p.once.Do(func() {
+		p.err = err
+		close(p.done)
+
+		// Wait for all senders to leave before closing the channel
+		p.mu.Lock()
+		close(p.ch)
+		p.mu.Unlock()
+	})
+	return nil
+}
+
+// Err returns the error the pipe was closed with.
+// It's nil if the pipe is open or closed without an error.
+func (p *Pipe[T]) Err() error {
+	select {
+	case <-p.done:
+		return p.err
+	default:
+		return nil
+	}
+}
+
+// Chan returns the channel to read messages from the pipe.
+// It's closed when the pipe is closed, use [Pipe.Err] to get the close error.
+func (p *Pipe[T]) Chan() <-chan T {
+	return p.ch
+}
+
+// Through connects the pipe with the stage built from other functions of the package,
+// and returns a new pipe with the stage output. The error of the source pipe is passed
+// to the returned pipe after the stage output is closed.
+// If the returned pipe is closed by the reader, the rest of the stage output is read to
+// the end in the background.
+// Creates a new pipe with the same capacity as source.
+//
+// # Usages
+//
+//	// src := NewPipe[int](4) closed with error after [1, 2, 3]
+//
+//	out := Through(src, func(in <-chan int) <-chan string {
+//	    return MapSync(func(value int) string {
+//	        return fmt.Sprintf("val: %d", value)
+//	    }, in)
+//	})
+//
+//	// out.Chan(): ["val: 1", "val: 2", "val: 3"]
+//	// out.Err(): the error of src
+func Through[Tin, Tout any](src *Pipe[Tin], stage func(<-chan Tin) <-chan Tout) *Pipe[Tout] {
+	out := NewPipe[Tout](cap(src.ch))
+	stageOut := stage(src.Chan())
+
+	go func() {
+		ctx := context.Background()
+		for {
+			if data, ok := <-stageOut; ok {
+				if err := out.Send(ctx, data); err != nil {
+					<-Wait(stageOut)
+					break
+				}
+			} else {
+				break
+			}
+		}
+		out.CloseWithError(src.Err())
+	}()
+
+	return out
+}
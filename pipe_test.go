@@ -0,0 +1,114 @@
+package pipe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestPipe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Close", func(t *testing.T) {
+		p := NewPipe[int](4)
+		go func() {
+			for i := 0; i < 3; i++ {
+				if err := p.Send(ctx, i); err != nil {
+					t.Error(err)
+				}
+			}
+			p.Close()
+		}()
+
+		for i := 0; i < 3; i++ {
+			if v, err := p.Recv(ctx); err != nil || v != i {
+				t.Fatalf("expected %d, got %d, %v", i, v, err)
+			}
+		}
+		if _, err := p.Recv(ctx); err != io.EOF {
+			t.Fatalf("expected EOF, got %v", err)
+		}
+	})
+
+	t.Run("CloseWithError", func(t *testing.T) {
+		errBroken := errors.New("broken")
+		p := NewPipe[int](4)
+		p.Send(ctx, 1)
+		p.CloseWithError(errBroken)
+		p.CloseWithError(errors.New("ignored"))
+
+		if v, err := p.Recv(ctx); err != nil || v != 1 {
+			t.Fatalf("expected buffered value, got %d, %v", v, err)
+		}
+		if _, err := p.Recv(ctx); err != errBroken {
+			t.Fatalf("expected close error, got %v", err)
+		}
+		if err := p.Send(ctx, 2); err != ErrClosedPipe {
+			t.Fatalf("expected ErrClosedPipe, got %v", err)
+		}
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		p := NewPipe[int](0)
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
+
+		if err := p.Send(ctx, 1); err != context.DeadlineExceeded {
+			t.Fatalf("expected deadline, got %v", err)
+		}
+		if _, err := p.Recv(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("expected deadline, got %v", err)
+		}
+	})
+
+	t.Run("CloseBlockedSend", func(t *testing.T) {
+		p := NewPipe[int](0)
+		sent := make(chan error)
+		go func() {
+			sent <- p.Send(ctx, 1)
+		}()
+		<-time.After(time.Millisecond)
+		p.Close()
+
+		if err := <-sent; err != ErrClosedPipe {
+			t.Fatalf("expected ErrClosedPipe, got %v", err)
+		}
+	})
+}
+
+func TestThrough(t *testing.T) {
+	ctx := context.Background()
+	errBroken := errors.New("broken")
+
+	src := NewPipe[int](16)
+	go func() {
+		for v := range test.Generator(0, 64, 16) {
+			src.Send(ctx, v)
+		}
+		src.CloseWithError(errBroken)
+	}()
+
+	out := Through(src, func(in <-chan int) <-chan int {
+		return FilterSync(func(val int) bool {
+			return val%2 == 0
+		}, in)
+	})
+
+	count := 0
+	for {
+		if _, err := out.Recv(ctx); err != nil {
+			if err != errBroken {
+				t.Fatalf("expected source error, got %v", err)
+			}
+			break
+		}
+		count++
+	}
+	if count != 32 {
+		t.Fatalf("expected 32 items, got %d", count)
+	}
+}
@@ -0,0 +1,207 @@
+package pipe
+
+import "reflect"
+
+// PriorityJoin takes several input channels and forwards their messages to the output channel.
+// Earlier inputs have higher priority: every time a message is sent, the first input which has
+// a pending message is used, so control messages can overtake bulk data. Be aware, a busy earlier
+// input can starve later inputs, use [WeightedJoin] to prevent it.
+// The message is chosen when the consumer is ready to receive it, so a slow consumer doesn't let
+// bulk data queue up before control messages.
+// If all input channels are closed then the output channel is closed.
+// Creates a new channel without capacity, messages wait in the input channels.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: All
+//
+// # Usages
+//
+//	// control := make(chan int, 4) with values [1, 2]
+//	// bulk := make(chan int, 4) with values [10, 20, 30]
+//
+//	output := PriorityJoin(control, bulk)
+//
+//	// output: [1, 2, 10, 20, 30]
+func PriorityJoin[T any](ins ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		joiner := newJoiner(ins)
+		for joiner.open > 0 {
+			best := -1
+			for i := range ins {
+				if joiner.poll(i) {
+					best = i
+					break
+				}
+			}
+			if best < 0 {
+				joiner.wait(out, -1, len(ins))
+				continue
+			}
+			// Only earlier inputs can overtake the chosen message while the consumer is not ready.
+			joiner.wait(out, best, best)
+		}
+		close(out)
+	}()
+
+	return out
+}
+
+// WeightedJoin takes weights and input channels, and forwards messages of the inputs to the output channel.
+// When several inputs have pending messages, every input gets a share of the output proportional to its
+// weight, so low priority inputs are not starved. The shares are interleaved smoothly (weighted round robin),
+// inputs without pending messages don't block others.
+// The message is chosen when the consumer is ready to receive it.
+// If all input channels are closed then the output channel is closed.
+// Creates a new channel without capacity, messages wait in the input channels.
+// Panics if the number of weights is not equal to the number of inputs or any weight is less than 1.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: All
+//
+// # Usages
+//
+//	// control := make(chan int, 8) with values [1, 2, 3, 4, 5, 6]
+//	// bulk := make(chan int, 8) with values [10, 20, 30]
+//
+//	output := WeightedJoin([]int{2, 1}, control, bulk)
+//
+//	// output: [1, 10, 2, 3, 20, 4, 5, 30, 6]
+func WeightedJoin[T any](weights []int, ins ...<-chan T) <-chan T {
+	if len(weights) != len(ins) {
+		panic("pipe: number of weights must be equal to number of inputs")
+	}
+	for _, weight := range weights {
+		if weight < 1 {
+			panic("pipe: weight must be greater than 0")
+		}
+	}
+	out := make(chan T)
+
+	go func() {
+		joiner := newJoiner(ins)
+		current := make([]int, len(ins))
+		for joiner.open > 0 {
+			for i := range ins {
+				joiner.poll(i)
+			}
+
+			best, total := -1, 0
+			for i := range ins {
+				if joiner.has[i] {
+					total += weights[i]
+					if best < 0 || current[i]+weights[i] > current[best]+weights[best] {
+						best = i
+					}
+				}
+			}
+			if best < 0 {
+				joiner.wait(out, -1, len(ins))
+				continue
+			}
+			if joiner.wait(out, best, len(ins)) {
+				for i := range ins {
+					if joiner.has[i] || i == best {
+						current[i] += weights[i]
+					}
+				}
+				current[best] -= total
+			}
+		}
+		close(out)
+	}()
+
+	return out
+}
+
+// joiner keeps one pending message of every input channel of the join.
+type joiner[T any] struct {
+	ins   []<-chan T
+	heads []T
+	has   []bool
+	// open is the number of open inputs plus the number of pending messages.
+	open   int
+	closed []bool
+}
+
+func newJoiner[T any](ins []<-chan T) *joiner[T] {
+	return &joiner[T]{
+		ins:    ins,
+		heads:  make([]T, len(ins)),
+		has:    make([]bool, len(ins)),
+		open:   len(ins),
+		closed: make([]bool, len(ins)),
+	}
+}
+
+// poll receives the pending message of the input without blocking, if there is no one yet.
+// Returns true if the input has a pending message.
+func (j *joiner[T]) poll(i int) bool {
+	if j.has[i] || j.closed[i] {
+		return j.has[i]
+	}
+	select {
+	case data, ok := <-j.ins[i]:
+		j.receive(i, data, ok)
+	default:
+	}
+	return j.has[i]
+}
+
+// wait blocks until one of the open inputs before the limit without a pending message receives a message
+// or is closed, or, if the index is not negative, the pending message of the input is sent to the output.
+// Returns true if the message is sent.
+func (j *joiner[T]) wait(out chan<- T, index int, limit int) bool {
+	cases := make([]reflect.SelectCase, 0, limit+1)
+	inputs := make([]int, 0, limit+1)
+	if index >= 0 {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(out), Send: reflect.ValueOf(&j.heads[index]).Elem()})
+		inputs = append(inputs, -1)
+	}
+	for i := 0; i < limit; i++ {
+		if !j.closed[i] && !j.has[i] {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(j.ins[i])})
+			inputs = append(inputs, i)
+		}
+	}
+	if len(cases) == 0 {
+		return false
+	}
+	chosen, value, ok := reflect.Select(cases)
+	if inputs[chosen] < 0 {
+		j.take(index)
+		return true
+	}
+	var data T
+	if ok {
+		data, _ = value.Interface().(T)
+	}
+	j.receive(inputs[chosen], data, ok)
+	return false
+}
+
+func (j *joiner[T]) receive(i int, data T, ok bool) {
+	if ok {
+		j.heads[i] = data
+		j.has[i] = true
+		j.open++
+	} else {
+		j.closed[i] = true
+		j.open--
+	}
+}
+
+// take removes and returns the pending message of the input.
+func (j *joiner[T]) take(i int) T {
+	var zero T
+	data := j.heads[i]
+	j.heads[i] = zero
+	j.has[i] = false
+	j.open--
+	return data
+}
@@ -0,0 +1,115 @@
+package pipe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+// filled returns a closed channel with the given values.
+func filled(capacity int, values ...int) <-chan int {
+	ch := make(chan int, capacity)
+	for _, v := range values {
+		ch <- v
+	}
+	close(ch)
+	return ch
+}
+
+func TestPriorityJoin(t *testing.T) {
+	t.Run("Priority", func(t *testing.T) {
+		out := PriorityJoin(filled(4, 10, 20, 30), filled(4, 1, 2))
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, []int{10, 20, 30, 1, 2}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+
+	t.Run("Backlog", func(t *testing.T) {
+		control := make(chan int, 1)
+		bulk := make(chan int, 64)
+		for i := 0; i < 64; i++ {
+			bulk <- i + 100
+		}
+		out := PriorityJoin(control, bulk)
+
+		if v := <-out; v != 100 {
+			t.Fatalf("expected 100, got %d", v)
+		}
+		control <- 1
+		// The control message is taken by the stage before the consumer is ready.
+		for len(control) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if v := <-out; v != 1 {
+			t.Errorf("expected control message to overtake the backlog, got %d", v)
+		}
+		close(control)
+		close(bulk)
+		count := 0
+		for range out {
+			count++
+		}
+		if count != 63 {
+			t.Errorf("expected 63 remaining messages, got %d", count)
+		}
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := PriorityJoin(test.Generator(0, 64, 16), test.Generator(100, 164, 0), test.Generator(200, 264, 4))
+
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 192)
+
+			return []<-chan int{pipe}, epipe
+		})
+	})
+}
+
+func TestWeightedJoin(t *testing.T) {
+	t.Run("Weights", func(t *testing.T) {
+		out := WeightedJoin([]int{2, 1}, filled(8, 1, 2, 3, 4, 5, 6), filled(8, 10, 20, 30))
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, []int{1, 10, 2, 3, 20, 4, 5, 30, 6}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		out := WeightedJoin([]int{1, 5}, filled(8, 1, 2, 3), filled(0))
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := WeightedJoin([]int{3, 1}, test.Generator(0, 64, 16), test.Generator(100, 164, 0))
+
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 128)
+
+			return []<-chan int{pipe}, epipe
+		})
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		WeightedJoin([]int{1}, filled(0), filled(0))
+	})
+}
@@ -0,0 +1,165 @@
+package pipe
+
+import "time"
+
+// queuedItem is the message of the queue with its arrival number, equal messages keep the arrival order.
+type queuedItem[T any] struct {
+	seq  uint64
+	at   time.Time
+	data T
+}
+
+// PriorityBuffer takes message and keeps it in the buffer of the given size until the output channel is read.
+// Every time the consumer is ready, the best buffered message (the least by less function) is sent.
+// Equal messages are sent in the order they were received. If the buffer is full then the producer is blocked.
+// If input channel is closed then all buffered messages are sent in the priority order and output channel is closed.
+// Creates a new channel without capacity, messages are buffered inside the stage.
+// Panics if the size is less than 1.
+//
+// The stage doesn't depend on time, so unlike [DelayQueue] it has no clock.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//
+// # Usages
+//
+//	// input := make(chan Task, 4) with values [{low}, {high}, {normal}] received before the consumer is ready
+//
+//	output := PriorityBuffer(16, func(a, b Task) bool {
+//	    return a.Priority > b.Priority
+//	}, input)
+//
+//	// output: [{high}, {normal}, {low}]
+func PriorityBuffer[T any](size int, less func(a, b T) bool, in <-chan T) <-chan T {
+	if size < 1 {
+		panic("buffer size must be positive")
+	}
+	out := make(chan T)
+
+	go func() {
+		queue := newPriorityQueue(func(a, b queuedItem[T]) bool {
+			if less(a.data, b.data) {
+				return true
+			}
+			return !less(b.data, a.data) && a.seq < b.seq
+		})
+		var seq uint64
+		for in != nil || queue.Len() > 0 {
+			var output chan T
+			var head T
+			if queue.Len() > 0 {
+				output = out
+				head = queue.peek().data
+			}
+			input := in
+			if queue.Len() >= size {
+				input = nil
+			}
+
+			select {
+			case data, ok := <-input:
+				if !ok {
+					in = nil
+					break
+				}
+				queue.push(queuedItem[T]{seq: seq, data: data})
+				seq++
+			case output <- head:
+				queue.pop()
+			}
+		}
+		close(out)
+	}()
+
+	return out
+}
+
+// DelayConfig configures [DelayQueue].
+type DelayConfig struct {
+	// Clock is the source of time. By default the [SystemClock] is used.
+	Clock Clock
+}
+
+// DelayQueue takes message and sends it to the output channel at the time returned by at function.
+// Messages with the same time are sent in the order they were received, messages with time in the past
+// are sent immediately.
+// If input channel is closed then the remaining messages are still sent at their time and then output
+// channel is closed.
+// Creates a new channel without capacity, messages are buffered inside the stage.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//
+// # Usages
+//
+//	// input := make(chan Retry) with values [{a +3s}, {b +1s}, {c +2s}]
+//
+//	output := DelayQueue(DelayConfig{}, func(r Retry) time.Time {
+//	    return r.At
+//	}, input)
+//
+//	// output: [{b +1s}, {c +2s}, {a +3s}]
+func DelayQueue[T any](config DelayConfig, at func(T) time.Time, in <-chan T) <-chan T {
+	clock := clockOrSystem(config.Clock)
+	out := make(chan T)
+
+	go func() {
+		queue := newPriorityQueue(func(a, b queuedItem[T]) bool {
+			if !a.at.Equal(b.at) {
+				return a.at.Before(b.at)
+			}
+			return a.seq < b.seq
+		})
+		var seq uint64
+		// timer waits for the first message, it's stopped when an earlier message is received
+		var timer Timer
+		var expired <-chan time.Time
+		var armed time.Time
+		stop := func() {
+			if timer != nil {
+				timer.Stop()
+				timer, expired = nil, nil
+			}
+		}
+		for in != nil || queue.Len() > 0 {
+			var output chan T
+			var head T
+			if queue.Len() > 0 {
+				next := queue.peek()
+				if delay := next.at.Sub(clock.Now()); delay > 0 {
+					if timer == nil || !armed.Equal(next.at) {
+						stop()
+						timer = clock.NewTimer(delay)
+						expired = timer.C()
+						armed = next.at
+					}
+				} else {
+					stop()
+					output = out
+					head = next.data
+				}
+			}
+
+			select {
+			case data, ok := <-in:
+				if !ok {
+					in = nil
+					break
+				}
+				queue.push(queuedItem[T]{seq: seq, at: at(data), data: data})
+				seq++
+			case output <- head:
+				queue.pop()
+			case <-expired:
+				timer, expired = nil, nil
+			}
+		}
+		close(out)
+	}()
+
+	return out
+}
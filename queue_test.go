@@ -0,0 +1,113 @@
+package pipe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestPriorityBuffer(t *testing.T) {
+	type task struct {
+		priority int
+		id       int
+	}
+
+	t.Run("Priority", func(t *testing.T) {
+		in := make(chan task)
+		out := PriorityBuffer(16, func(a, b task) bool {
+			return a.priority > b.priority
+		}, in)
+
+		for i, priority := range []int{1, 3, 2, 3, 1} {
+			in <- task{priority: priority, id: i}
+		}
+		close(in)
+
+		var got []task
+		for v := range out {
+			got = append(got, v)
+		}
+		want := []task{{3, 1}, {3, 3}, {2, 2}, {1, 0}, {1, 4}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := PriorityBuffer(4, func(a, b int) bool {
+				return a < b
+			}, test.Generator(0, 64, 16))
+
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 64)
+
+			return []<-chan int{pipe}, epipe
+		})
+	})
+}
+
+func TestDelayQueue(t *testing.T) {
+	type retry struct {
+		name string
+		at   time.Time
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := test.NewClock(start)
+	in := make(chan retry)
+	out := DelayQueue(DelayConfig{Clock: clock}, func(r retry) time.Time {
+		return r.at
+	}, in)
+
+	send := func(name string, delay time.Duration) {
+		in <- retry{name: name, at: start.Add(delay)}
+	}
+	expect := func(names ...string) {
+		for _, name := range names {
+			select {
+			case v := <-out:
+				if v.name != name {
+					t.Fatalf("expected %s, got %s", name, v.name)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected %s, got nothing", name)
+			}
+		}
+		select {
+		case v, ok := <-out:
+			if ok {
+				t.Fatalf("unexpected %s", v.name)
+			}
+		default:
+		}
+	}
+
+	// Every send after an expected message makes sure the stage has armed the timer for the next one.
+	send("a", 3*time.Second)
+	send("b", time.Second)
+	send("c", 2*time.Second)
+	send("d", -time.Second)
+	expect("d")
+
+	send("e", 3*time.Second)
+	if clock.Timers() != 1 {
+		t.Fatalf("expected the timer of a to be stopped, got %d timers", clock.Timers())
+	}
+	clock.Advance(time.Second)
+	expect("b")
+
+	send("f", 2*time.Second)
+	clock.Advance(time.Second)
+	expect("c", "f")
+
+	send("g", 3*time.Second)
+	close(in)
+	clock.Advance(time.Second)
+	expect("a", "e", "g")
+
+	if _, ok := <-out; ok {
+		t.Error("expected closed output")
+	}
+}
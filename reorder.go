@@ -0,0 +1,152 @@
+package pipe
+
+import "time"
+
+// defaultReorderWindow is the window of [Reorder] if it's not configured.
+const defaultReorderWindow = 1024
+
+// Indexed is the value with its sequence number.
+type Indexed[T any] struct {
+	Index uint64
+	Value T
+}
+
+// Enumerate takes value and attaches the sequence number to it, starting from 0.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan string, 4) with values ["a", "b", "c"]
+//
+//	output := Enumerate(input)
+//
+//	// output: [{0 a}, {1 b}, {2 c}]
+func Enumerate[T any](in <-chan T) <-chan Indexed[T] {
+	var index uint64
+	return MapSequential(func(value T) Indexed[T] {
+		item := Indexed[T]{Index: index, Value: value}
+		index++
+		return item
+	}, in)
+}
+
+// ReorderConfig configures [Reorder].
+type ReorderConfig struct {
+	// Window is the maximum number of values waiting for a missing sequence number.
+	// If it's exceeded then the missing number is skipped. By default it's 1024.
+	Window int
+	// Timeout is the maximum time to wait for a missing sequence number before it's skipped.
+	// If it's 0 then the missing number is skipped only when the window is exceeded.
+	Timeout time.Duration
+	// Clock is the source of time. By default the [SystemClock] is used.
+	Clock Clock
+}
+
+// Reorder takes indexed value and forwards the values in the order of sequence numbers, starting from 0.
+// Values which come before their turn wait for the missing numbers. The missing number is skipped when
+// the window is exceeded or it's awaited longer than the timeout. Values with skipped numbers are dropped.
+// If input channel is closed then all waiting values are sent in order and output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan string, 4) with values ["a", "b", "c", "d"]
+//
+//	output := Reorder(ReorderConfig{Window: 16}, Map(func(item Indexed[string]) Indexed[string] {
+//	    fmt.Print(item.Value)
+//	    return Indexed[string]{Index: item.Index, Value: strings.ToUpper(item.Value)}
+//	}, Enumerate(input)))
+//
+//	// stdout: c a d b
+//	// output: ["A", "B", "C", "D"]
+func Reorder[T any](config ReorderConfig, in <-chan Indexed[T]) <-chan T {
+	if config.Window <= 0 {
+		config.Window = defaultReorderWindow
+	}
+	out := make(chan T, cap(in))
+	clock := clockOrSystem(config.Clock)
+
+	go func() {
+		var next uint64
+		waiting := map[uint64]T{}
+		indexes := newPriorityQueue(func(a, b uint64) bool {
+			return a < b
+		})
+
+		// release sends waiting values until the next missing number
+		release := func() {
+			for indexes.Len() > 0 && indexes.peek() == next {
+				indexes.pop()
+				out <- waiting[next]
+				delete(waiting, next)
+				next++
+			}
+		}
+		// skip moves to the first waiting value
+		skip := func() {
+			if indexes.Len() > 0 {
+				next = indexes.peek()
+				release()
+			}
+		}
+
+		// timer waits for the missing number gap, it's stopped when the gap is filled or skipped
+		var timer Timer
+		var expired <-chan time.Time
+		var gap uint64
+		for {
+			select {
+			case item, ok := <-in:
+				if !ok {
+					if timer != nil {
+						timer.Stop()
+					}
+					for indexes.Len() > 0 {
+						skip()
+					}
+					close(out)
+					return
+				}
+				if _, ok := waiting[item.Index]; ok || item.Index < next {
+					break
+				}
+				waiting[item.Index] = item.Value
+				indexes.push(item.Index)
+				release()
+				if indexes.Len() > config.Window {
+					skip()
+				}
+			case <-expired:
+				timer, expired = nil, nil
+				skip()
+			}
+
+			if config.Timeout > 0 {
+				if timer != nil && (indexes.Len() == 0 || gap != next) {
+					timer.Stop()
+					timer, expired = nil, nil
+				}
+				if timer == nil && indexes.Len() > 0 {
+					gap = next
+					timer = clock.NewTimer(config.Timeout)
+					expired = timer.C()
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,88 @@
+package pipe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestReorder(t *testing.T) {
+	collect := func(out <-chan int) string {
+		var results []int
+		for data := range out {
+			results = append(results, data)
+		}
+		return fmt.Sprint(results)
+	}
+
+	t.Run("Parallel", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := Enumerate(test.Generator(0, 64, 16))
+
+			pipe = Map(func(item Indexed[int]) Indexed[int] {
+				return item
+			}, pipe)
+			pipe2 := Reorder(ReorderConfig{}, pipe)
+
+			pipe2, epipe = test.AssertOrderAsc("ordering", pipe2, epipe)
+			pipe2, epipe = test.AssertCount("count", pipe2, epipe, 64)
+
+			return []<-chan int{pipe2}, epipe
+		})
+	})
+
+	t.Run("Window", func(t *testing.T) {
+		in := make(chan Indexed[int], 8)
+		for _, index := range []uint64{1, 2, 3, 0, 5, 4} {
+			in <- Indexed[int]{Index: index, Value: int(index)}
+		}
+		close(in)
+
+		if results := collect(Reorder(ReorderConfig{Window: 2}, in)); results != "[1 2 3 4 5]" {
+			t.Fatalf("expected [1 2 3 4 5], got %s", results)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		clock := test.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		in := make(chan Indexed[int])
+		out := Reorder(ReorderConfig{Timeout: time.Second, Clock: clock}, in)
+
+		in <- Indexed[int]{Index: 1, Value: 1}
+		in <- Indexed[int]{Index: 3, Value: 3}
+		clock.Advance(time.Second)
+		if data := <-out; data != 1 {
+			t.Fatalf("expected 1, got %d", data)
+		}
+
+		in <- Indexed[int]{Index: 0, Value: 0}
+		in <- Indexed[int]{Index: 2, Value: 2}
+		close(in)
+		if results := collect(out); results != "[2 3]" {
+			t.Fatalf("expected [2 3], got %s", results)
+		}
+	})
+
+	t.Run("TimeoutTimers", func(t *testing.T) {
+		clock := test.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		in := make(chan Indexed[int])
+		out := Reorder(ReorderConfig{Timeout: time.Second, Clock: clock}, in)
+
+		for i := 0; i < 100; i++ {
+			in <- Indexed[int]{Index: uint64(2*i + 1), Value: 2*i + 1}
+			in <- Indexed[int]{Index: uint64(2 * i), Value: 2 * i}
+			<-out
+			<-out
+			if clock.Timers() > 1 {
+				t.Fatalf("expected at most 1 timer, got %d", clock.Timers())
+			}
+		}
+		close(in)
+		collect(out)
+		if clock.Timers() != 0 {
+			t.Fatalf("expected no timers, got %d", clock.Timers())
+		}
+	})
+}
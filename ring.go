@@ -0,0 +1,46 @@
+package pipe
+
+// ring is a FIFO queue based on the ring buffer, it grows when it's full.
+type ring[T any] struct {
+	items []T
+	head  int
+	size  int
+}
+
+// len returns the number of items in the queue.
+func (r *ring[T]) len() int {
+	return r.size
+}
+
+// push adds the item to the end of the queue.
+func (r *ring[T]) push(item T) {
+	if r.size == len(r.items) {
+		items := make([]T, 2*len(r.items)+1)
+		n := copy(items, r.items[r.head:])
+		copy(items[n:], r.items[:r.head])
+		r.items = items
+		r.head = 0
+	}
+	r.items[(r.head+r.size)%len(r.items)] = item
+	r.size++
+}
+
+// peek returns the first item of the queue without removing it.
+func (r *ring[T]) peek() T {
+	return r.items[r.head]
+}
+
+// shift removes and returns the first item of the queue.
+func (r *ring[T]) shift() T {
+	var zero T
+	item := r.items[r.head]
+	r.items[r.head] = zero
+	r.head = (r.head + 1) % len(r.items)
+	r.size--
+	return item
+}
+
+// at returns the item with the given position in the queue.
+func (r *ring[T]) at(i int) T {
+	return r.items[(r.head+i)%len(r.items)]
+}
@@ -0,0 +1,29 @@
+package pipe
+
+// Scan takes message, applies the accumulator function to the current state and the message,
+// and sends the new state to the output channel. The first state is the seed.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4]
+//
+//	output := Scan(0, func(total, value int) int {
+//	    return total + value
+//	}, input)
+//
+//	// output: [1, 3, 6, 10]
+func Scan[T, A any](seed A, accumulator func(A, T) A, in <-chan T) <-chan A {
+	state := seed
+	return MapSequential(func(data T) A {
+		state = accumulator(state, data)
+		return state
+	}, in)
+}
@@ -0,0 +1,37 @@
+package pipe
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestScan(t *testing.T) {
+	t.Run("Total", func(t *testing.T) {
+		out := Scan(0, func(total, value int) int {
+			return total + value
+		}, filled(4, 1, 2, 3, 4))
+
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, []int{1, 3, 6, 10}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+
+	t.Run("Ordering", func(t *testing.T) {
+		test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+			pipe := Scan(-1, func(_, value int) int {
+				return value
+			}, test.Generator(0, 64, 16))
+
+			pipe, epipe = test.AssertOrderAsc("ordering", pipe, epipe)
+			pipe, epipe = test.AssertCount("count", pipe, epipe, 64)
+
+			return []<-chan int{pipe}, epipe
+		})
+	})
+}
@@ -0,0 +1,264 @@
+package pipe
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// Default values of [SpillConfig].
+const (
+	defaultSpillMemory      = 1024
+	defaultSpillSegmentSize = 64 * 1024
+)
+
+// SpillConfig configures [NewSpillBuffer].
+type SpillConfig struct {
+	// Dir is the directory where the temporary directory with segment files is created.
+	// By default the [os.TempDir] is used.
+	Dir string
+	// Memory is the number of messages kept in memory before they are spilled to disk. By default it's 1024.
+	Memory int
+	// SegmentSize is the number of messages in one segment file. By default it's 65536.
+	SegmentSize int
+	// Codec encodes messages in segment files. By default the [GobCodec] is used.
+	Codec Codec
+}
+
+// spillSegment is the file with spilled messages.
+type spillSegment struct {
+	path  string
+	count int
+}
+
+// spillWriter writes messages to the last segment.
+type spillWriter struct {
+	segment *spillSegment
+	file    *os.File
+	buf     *bufio.Writer
+	encoder Encoder
+}
+
+// spillReader reads messages from the first segment.
+type spillReader struct {
+	segment *spillSegment
+	file    *os.File
+	decoder Decoder
+}
+
+// SpillBuffer is the stage created by [NewSpillBuffer].
+type SpillBuffer[T any] struct {
+	length  int64
+	spilled int64
+	config  SpillConfig
+	dir     string
+	out     chan T
+	mu      sync.Mutex
+	err     error
+
+	segments ring[*spillSegment]
+	next     int
+	writer   *spillWriter
+	reader   *spillReader
+}
+
+// NewSpillBuffer creates the buffer which takes message and keeps it in memory until the output channel is read.
+// If there are more messages than the memory limit then they are spilled to segment files on disk, and
+// read back in the same order. Segment files are removed after they are read.
+// If input channel is closed then all buffered messages are sent and output channel is closed.
+// If disk operation fails then the error is available by [SpillBuffer.Err], the rest of input channel
+// is read to the end in the background and output channel is closed.
+// Creates a new channel without capacity, messages are buffered inside the stage.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//
+// # Usages
+//
+//	// input := make(chan Event, 1024) with millions of events during the downstream outage
+//
+//	output, err := NewSpillBuffer(SpillConfig{Dir: "/var/spool/app", Memory: 10000}, input)
+//
+//	// output.Chan(): all events in the same order
+//	// output.Spilled(): events which are waiting on disk
+func NewSpillBuffer[T any](config SpillConfig, in <-chan T) (*SpillBuffer[T], error) {
+	if config.Memory <= 0 {
+		config.Memory = defaultSpillMemory
+	}
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = defaultSpillSegmentSize
+	}
+	if config.Codec == nil {
+		config.Codec = GobCodec
+	}
+	dir, err := os.MkdirTemp(config.Dir, "spill-")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &SpillBuffer[T]{config: config, dir: dir, out: make(chan T)}
+	go b.run(in)
+	return b, nil
+}
+
+// Chan returns the output channel of the stage.
+func (b *SpillBuffer[T]) Chan() <-chan T {
+	return b.out
+}
+
+// Len returns the number of buffered messages in memory and on disk.
+func (b *SpillBuffer[T]) Len() int {
+	return int(atomic.LoadInt64(&b.length))
+}
+
+// Spilled returns the number of messages on disk.
+func (b *SpillBuffer[T]) Spilled() int {
+	return int(atomic.LoadInt64(&b.spilled))
+}
+
+// Err returns the error of disk operation which stopped the buffer.
+func (b *SpillBuffer[T]) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+func (b *SpillBuffer[T]) run(in <-chan T) {
+	memory := ring[T]{}
+	fail := func(err error) {
+		b.mu.Lock()
+		b.err = err
+		b.mu.Unlock()
+		if in != nil {
+			go drain(in)
+			in = nil
+		}
+	}
+
+	for in != nil || memory.len() > 0 || (b.Spilled() > 0 && b.Err() == nil) {
+		if memory.len() == 0 && b.Spilled() > 0 && b.Err() == nil {
+			data, err := b.read()
+			if err != nil {
+				fail(err)
+				continue
+			}
+			memory.push(data)
+		}
+
+		var out chan T
+		var head T
+		if memory.len() > 0 {
+			out = b.out
+			head = memory.peek()
+		}
+
+		select {
+		case data, ok := <-in:
+			if !ok {
+				in = nil
+				break
+			}
+			atomic.AddInt64(&b.length, 1)
+			if b.Spilled() == 0 && memory.len() < b.config.Memory {
+				memory.push(data)
+			} else if err := b.write(data); err != nil {
+				atomic.AddInt64(&b.length, -1)
+				fail(err)
+			}
+		case out <- head:
+			memory.shift()
+			atomic.AddInt64(&b.length, -1)
+		}
+	}
+
+	b.close()
+	close(b.out)
+}
+
+// write appends the message to the last segment, a new segment is created if it's full.
+func (b *SpillBuffer[T]) write(data T) error {
+	if b.writer != nil && b.writer.segment.count >= b.config.SegmentSize {
+		if err := b.seal(); err != nil {
+			return err
+		}
+	}
+	if b.writer == nil {
+		segment := &spillSegment{path: filepath.Join(b.dir, fmt.Sprintf("segment-%08d", b.next))}
+		b.next++
+		file, err := os.Create(segment.path)
+		if err != nil {
+			return err
+		}
+		buf := bufio.NewWriter(file)
+		b.writer = &spillWriter{segment: segment, file: file, buf: buf, encoder: b.config.Codec.NewEncoder(buf)}
+		b.segments.push(segment)
+	}
+
+	if err := b.writer.encoder.Encode(data); err != nil {
+		return err
+	}
+	b.writer.segment.count++
+	atomic.AddInt64(&b.spilled, 1)
+	return nil
+}
+
+// seal flushes and closes the last segment.
+func (b *SpillBuffer[T]) seal() error {
+	w := b.writer
+	b.writer = nil
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// read returns the next message from the first segment, the segment is removed when it's read.
+func (b *SpillBuffer[T]) read() (T, error) {
+	var data T
+	if b.reader == nil {
+		segment := b.segments.peek()
+		if b.writer != nil && b.writer.segment == segment {
+			if err := b.seal(); err != nil {
+				return data, err
+			}
+		}
+		file, err := os.Open(segment.path)
+		if err != nil {
+			return data, err
+		}
+		b.reader = &spillReader{segment: segment, file: file, decoder: b.config.Codec.NewDecoder(bufio.NewReader(file))}
+	}
+
+	if err := b.reader.decoder.Decode(&data); err != nil {
+		return data, err
+	}
+	atomic.AddInt64(&b.spilled, -1)
+	if b.reader.segment.count--; b.reader.segment.count == 0 {
+		b.segments.shift()
+		b.reader.file.Close()
+		os.Remove(b.reader.segment.path)
+		b.reader = nil
+	}
+	return data, nil
+}
+
+// close releases all files and removes the temporary directory.
+func (b *SpillBuffer[T]) close() {
+	if b.writer != nil {
+		b.writer.file.Close()
+	}
+	if b.reader != nil {
+		b.reader.file.Close()
+	}
+	if err := os.RemoveAll(b.dir); err != nil && b.Err() == nil {
+		b.mu.Lock()
+		b.err = err
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,40 @@
+package pipe
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+func TestSpillBuffer(t *testing.T) {
+	for name, codec := range map[string]Codec{"Gob": GobCodec, "JSON": JSONCodec} {
+		codec := codec
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			in := test.Generator(0, 1000, 16)
+			b, err := NewSpillBuffer(SpillConfig{Dir: dir, Memory: 4, SegmentSize: 10, Codec: codec}, in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for b.Spilled() < 100 {
+				time.Sleep(time.Millisecond)
+			}
+
+			test.Suit(t, func(epipe chan error) ([]<-chan int, chan error) {
+				pipe, epipe := test.AssertOrderAsc("ordering", b.Chan(), epipe)
+				pipe, epipe = test.AssertCount("count", pipe, epipe, 1000)
+				return []<-chan int{pipe}, epipe
+			})
+
+			if b.Err() != nil {
+				t.Fatal(b.Err())
+			}
+			if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+				t.Fatalf("expected empty directory, got %v, %v", entries, err)
+			}
+		})
+	}
+}
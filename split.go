@@ -1,16 +1,16 @@
 package pipe
 
-import (
-	"sync"
-)
-
 // Split takes a number of output channels and input channel, and forwards the input
 // messages to all output channels.
-// There is no guarantee that the message will be sent to the output channels in the
-// sequence in which they are provided.
+// Outputs don't wait for each other: every output has its own buffer which grows while its consumer
+// is slow, so a slow output doesn't block others, but be aware of the memory it takes.
+// Every output receives messages in the same order as the input, but there is no guarantee
+// that the message will be sent to the output channels in the sequence in which they are provided.
 // If input channel is closed then all output channels are closed.
 // Creates new channels with the same capacity as input.
 //
+// It's [Broadcast] with the [GrowLagging] policy, use [Broadcast] to choose another slow consumer policy.
+//
 // # Strategies
 //
 //   - Processing: Parallel
@@ -19,46 +19,15 @@ import (
 //
 // # Usages
 //
-//	// input := make(chan int, 4) with random values [1, 2, 3]
+//	// input := make(chan int, 4) with values [1, 2, 3]
 //
 //	outs := Split(2, input)
 //
 //	// The gaps demonstrate uneven recording in the channels
-//	// outs[0]: [2,    1, 3   ]
-//	// outs[1]: [   1, 3,    2]
+//	// outs[0]: [1, 2, 3      ]
+//	// outs[1]: [      1, 2, 3]
 func Split[T any](n int, in <-chan T) []<-chan T {
-	outs := make([]chan T, n)
-	for i := 0; i < n; i++ {
-		outs[i] = make(chan T, cap(in))
-	}
-	wg := sync.WaitGroup{}
-
-	go func() {
-		for {
-			if in, ok := <-in; ok {
-				for i := 0; i < n; i++ {
-					i := i
-					wg.Add(1)
-					go func() {
-						outs[i] <- in
-						wg.Done()
-					}()
-				}
-			} else {
-				wg.Wait()
-				for i := 0; i < n; i++ {
-					close(outs[i])
-				}
-				break
-			}
-		}
-	}()
-
-	outsR := make([]<-chan T, n)
-	for i := 0; i < n; i++ {
-		outsR[i] = outs[i]
-	}
-	return outsR
+	return Broadcast(n, GrowLagging, in)
 }
 
 // Split2 - alias for [Split]
@@ -80,6 +49,11 @@ func Split3[T any](in <-chan T) (out1, out2, out3 <-chan T) {
 // If input channel is closed then all output channels are closed.
 // Creates new channels with the same capacity as input.
 //
+// Every output has its own buffer of the input channel capacity (at least 1). Be aware, if the buffer
+// of one of the output channels is full, then all other output channels will wait.
+//
+// It's [Broadcast] with the [BlockAll] policy.
+//
 // # Strategies
 //
 //   - Processing: Sync
@@ -96,63 +70,7 @@ func Split3[T any](in <-chan T) (out1, out2, out3 <-chan T) {
 //	// outs[0]: [1,    2, 3   ]
 //	// outs[1]: [   1, 2,    3]
 func SplitSync[T any](n int, in <-chan T) []<-chan T {
-	outs := make([]chan T, n)
-	for i := 0; i < n; i++ {
-		outs[i] = make(chan T, cap(in))
-	}
-	queues := make([]chan func() <-chan T, n)
-	for i := 0; i < n; i++ {
-		queues[i] = make(chan func() <-chan T, cap(in))
-	}
-	wg := sync.WaitGroup{}
-
-	go func() {
-		for {
-			if in, ok := <-in; ok {
-				for i := 0; i < n; i++ {
-					i := i
-					wg.Add(1)
-					queues[i] <- func() <-chan T {
-						out := make(chan T)
-						go func() {
-							out <- in
-							close(out)
-							wg.Done()
-						}()
-						return out
-					}
-				}
-			} else {
-				wg.Wait()
-				for i := 0; i < n; i++ {
-					close(queues[i])
-				}
-				break
-			}
-		}
-	}()
-
-	for i := 0; i < n; i++ {
-		i := i
-		go func() {
-			for {
-				if handler, ok := <-queues[i]; ok {
-					if data, ok := <-handler(); ok {
-						outs[i] <- data
-					}
-				} else {
-					close(outs[i])
-					break
-				}
-			}
-		}()
-	}
-
-	outsR := make([]<-chan T, n)
-	for i := 0; i < n; i++ {
-		outsR[i] = outs[i]
-	}
-	return outsR
+	return Broadcast(n, BlockAll, in)
 }
 
 // SplitSync2 - alias for [SplitSync]
@@ -0,0 +1,88 @@
+package pipe
+
+// syncSlot keeps the result of the message until its turn to be sent.
+type syncSlot[T any] struct {
+	value T
+	ok    bool
+	last  bool
+	ready chan struct{}
+}
+
+// syncJob is the message to process and the index of the slot for its result.
+type syncJob[T any] struct {
+	slot int
+	data T
+}
+
+// syncProcess implements Sync processing strategy. It processes messages of the input channel by a fixed
+// pool of workers and passes results accepted by the handler to emit function in the same order as
+// the input. Results are kept in the ring of slots of the input channel capacity, so no more messages
+// are processed at the same time. If input channel capacity is 0 then messages are processed sequentially.
+// Returns when input channel is closed and all results are passed.
+func syncProcess[Tin, Tout any](in <-chan Tin, handle func(Tin) (Tout, bool), emit func(Tout)) {
+	size := cap(in)
+	if size == 0 {
+		for {
+			if data, ok := <-in; ok {
+				if result, ok := handle(data); ok {
+					emit(result)
+				}
+			} else {
+				return
+			}
+		}
+	}
+
+	slots := make([]syncSlot[Tout], size)
+	free := make(chan struct{}, size)
+	for i := range slots {
+		slots[i].ready = make(chan struct{}, 1)
+		free <- struct{}{}
+	}
+	jobs := make(chan syncJob[Tin], size)
+	done := make(chan struct{})
+
+	for i := 0; i < size; i++ {
+		go func() {
+			for {
+				if job, ok := <-jobs; ok {
+					slot := &slots[job.slot]
+					slot.value, slot.ok = handle(job.data)
+					slot.ready <- struct{}{}
+				} else {
+					break
+				}
+			}
+		}()
+	}
+
+	go func() {
+		var zero Tout
+		for i := 0; ; i = (i + 1) % size {
+			slot := &slots[i]
+			<-slot.ready
+			if slot.last {
+				close(done)
+				break
+			}
+			if slot.ok {
+				emit(slot.value)
+			}
+			slot.value = zero
+			free <- struct{}{}
+		}
+	}()
+
+	for next := 0; ; next = (next + 1) % size {
+		<-free
+		if data, ok := <-in; ok {
+			jobs <- syncJob[Tin]{slot: next, data: data}
+		} else {
+			close(jobs)
+			slots[next].last = true
+			slots[next].ready <- struct{}{}
+			<-done
+			return
+		}
+	}
+}
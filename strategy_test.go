@@ -0,0 +1,292 @@
+package pipe
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSyncProcess(t *testing.T) {
+	t.Run("Ordering", func(t *testing.T) {
+		in := make(chan int, 8)
+		go func() {
+			for i := 0; i < 256; i++ {
+				in <- i
+			}
+			close(in)
+		}()
+
+		var running, peak int64
+		var got []int
+		syncProcess(in, func(v int) (int, bool) {
+			n := atomic.AddInt64(&running, 1)
+			for {
+				p := atomic.LoadInt64(&peak)
+				if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(255-v%16) * time.Microsecond)
+			atomic.AddInt64(&running, -1)
+			return v, v%3 != 0
+		}, func(v int) {
+			got = append(got, v)
+		})
+
+		want := 0
+		for _, v := range got {
+			for want%3 == 0 {
+				want++
+			}
+			if v != want {
+				t.Fatalf("expected %d, got %d", want, v)
+			}
+			want++
+		}
+		if len(got) != 170 {
+			t.Errorf("expected 170 results, got %d", len(got))
+		}
+		if peak > 8 {
+			t.Errorf("expected at most 8 running handlers, got %d", peak)
+		}
+	})
+
+	t.Run("Unbuffered", func(t *testing.T) {
+		in := make(chan int)
+		go func() {
+			for i := 0; i < 16; i++ {
+				in <- i
+			}
+			close(in)
+		}()
+
+		var running int64
+		count := 0
+		syncProcess(in, func(v int) (int, bool) {
+			if atomic.AddInt64(&running, 1) > 1 {
+				t.Error("expected sequential processing")
+			}
+			atomic.AddInt64(&running, -1)
+			return v, true
+		}, func(v int) {
+			if v != count {
+				t.Errorf("expected %d, got %d", count, v)
+			}
+			count++
+		})
+		if count != 16 {
+			t.Errorf("expected 16 results, got %d", count)
+		}
+	})
+}
+
+// legacyMapSync is the previous implementation of MapSync with a closure, a channel and a goroutine per message.
+func legacyMapSync[Tin, Tout any](mapper func(Tin) Tout, in <-chan Tin) <-chan Tout {
+	out := make(chan Tout, cap(in))
+	queue := make(chan func() <-chan Tout, cap(in))
+	wg := sync.WaitGroup{}
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				wg.Add(1)
+				queue <- func() <-chan Tout {
+					out := make(chan Tout)
+					go func() {
+						out <- mapper(in)
+						close(out)
+						wg.Done()
+					}()
+					return out
+				}
+			} else {
+				wg.Wait()
+				close(queue)
+				break
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			if handler, ok := <-queue; ok {
+				if data, ok := <-handler(); ok {
+					out <- data
+				}
+			} else {
+				close(out)
+				break
+			}
+		}
+	}()
+
+	return out
+}
+
+// legacyFilterSync is the previous implementation of FilterSync.
+func legacyFilterSync[T any](filter func(T) bool, in <-chan T) <-chan T {
+	out := make(chan T, cap(in))
+	queue := make(chan func() <-chan T, cap(in))
+	wg := sync.WaitGroup{}
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				wg.Add(1)
+				queue <- func() <-chan T {
+					out := make(chan T)
+					go func() {
+						if filter(in) {
+							out <- in
+						}
+						close(out)
+						wg.Done()
+					}()
+					return out
+				}
+			} else {
+				wg.Wait()
+				close(queue)
+				break
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			if handler, ok := <-queue; ok {
+				if data, ok := <-handler(); ok {
+					out <- data
+				}
+			} else {
+				close(out)
+				break
+			}
+		}
+	}()
+
+	return out
+}
+
+// legacySplitSync is the previous implementation of SplitSync.
+func legacySplitSync[T any](n int, in <-chan T) []<-chan T {
+	outs := make([]chan T, n)
+	for i := 0; i < n; i++ {
+		outs[i] = make(chan T, cap(in))
+	}
+	queues := make([]chan func() <-chan T, n)
+	for i := 0; i < n; i++ {
+		queues[i] = make(chan func() <-chan T, cap(in))
+	}
+	wg := sync.WaitGroup{}
+
+	go func() {
+		for {
+			if in, ok := <-in; ok {
+				for i := 0; i < n; i++ {
+					wg.Add(1)
+					queues[i] <- func() <-chan T {
+						out := make(chan T)
+						go func() {
+							out <- in
+							close(out)
+							wg.Done()
+						}()
+						return out
+					}
+				}
+			} else {
+				wg.Wait()
+				for i := 0; i < n; i++ {
+					close(queues[i])
+				}
+				break
+			}
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		i := i
+		go func() {
+			for {
+				if handler, ok := <-queues[i]; ok {
+					if data, ok := <-handler(); ok {
+						outs[i] <- data
+					}
+				} else {
+					close(outs[i])
+					break
+				}
+			}
+		}()
+	}
+
+	outsR := make([]<-chan T, n)
+	for i := 0; i < n; i++ {
+		outsR[i] = outs[i]
+	}
+	return outsR
+}
+
+// benchInput returns a channel with capacity 64 which receives b.N messages.
+func benchInput(b *testing.B) <-chan int {
+	in := make(chan int, 64)
+	go func() {
+		for i := 0; i < b.N; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+	return in
+}
+
+func BenchmarkMapSync(b *testing.B) {
+	mapper := func(v int) int { return v * 2 }
+
+	b.Run("Legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		drain(legacyMapSync(mapper, benchInput(b)))
+	})
+	b.Run("Ring", func(b *testing.B) {
+		b.ReportAllocs()
+		drain(MapSync(mapper, benchInput(b)))
+	})
+}
+
+func BenchmarkFilterSync(b *testing.B) {
+	filter := func(v int) bool { return v%2 == 0 }
+
+	b.Run("Legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		drain(legacyFilterSync(filter, benchInput(b)))
+	})
+	b.Run("Ring", func(b *testing.B) {
+		b.ReportAllocs()
+		drain(FilterSync(filter, benchInput(b)))
+	})
+}
+
+func BenchmarkSplitSync(b *testing.B) {
+	run := func(b *testing.B, split func(int, <-chan int) []<-chan int) {
+		b.ReportAllocs()
+		outs := split(4, benchInput(b))
+		wg := sync.WaitGroup{}
+		for i := range outs {
+			i := i
+			wg.Add(1)
+			go func() {
+				drain(outs[i])
+				wg.Done()
+			}()
+		}
+		wg.Wait()
+	}
+
+	b.Run("Legacy", func(b *testing.B) {
+		run(b, legacySplitSync[int])
+	})
+	b.Run("Ring", func(b *testing.B) {
+		run(b, SplitSync[int])
+	})
+}
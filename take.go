@@ -0,0 +1,188 @@
+package pipe
+
+// Take takes the first n messages of the input channel and forwards them to the output channel.
+// After n messages the output channel is closed and the input channel is read to the end in the background.
+// If input channel is closed earlier then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5]
+//
+//	output := Take(3, input)
+//
+//	// output: [1, 2, 3]
+func Take[T any](n int, in <-chan T) <-chan T {
+	out := make(chan T, cap(in))
+
+	go func() {
+		for i := 0; i < n; i++ {
+			if data, ok := <-in; ok {
+				out <- data
+			} else {
+				close(out)
+				return
+			}
+		}
+		close(out)
+		drain(in)
+	}()
+
+	return out
+}
+
+// TakeWhile forwards messages of the input channel to the output channel while the predicate returns true.
+// The first message for which the predicate returns false is dropped, the output channel is closed
+// and the input channel is read to the end in the background.
+// If input channel is closed earlier then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 1, 2]
+//
+//	output := TakeWhile(func(value int) bool {
+//	    return value < 3
+//	}, input)
+//
+//	// output: [1, 2]
+func TakeWhile[T any](predicate func(T) bool, in <-chan T) <-chan T {
+	out := make(chan T, cap(in))
+
+	go func() {
+		for {
+			if data, ok := <-in; ok {
+				if !predicate(data) {
+					close(out)
+					drain(in)
+					break
+				}
+				out <- data
+			} else {
+				close(out)
+				break
+			}
+		}
+	}()
+
+	return out
+}
+
+// TakeUntil forwards messages of the input channel to the output channel until the signal channel
+// receives a value or is closed. Then the output channel is closed and the input channel is read
+// to the end in the background.
+// If input channel is closed earlier then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Any
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, (stop signal) 3, 4]
+//
+//	output := TakeUntil(stop, input)
+//
+//	// output: [1, 2]
+func TakeUntil[T any](signal <-chan struct{}, in <-chan T) <-chan T {
+	out := make(chan T, cap(in))
+
+	go func() {
+		for {
+			select {
+			case <-signal:
+				close(out)
+				drain(in)
+				return
+			case data, ok := <-in:
+				if !ok {
+					close(out)
+					return
+				}
+				select {
+				case out <- data:
+				case <-signal:
+					close(out)
+					drain(in)
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// Skip drops the first n messages of the input channel and forwards the rest to the output channel.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 4, 5]
+//
+//	output := Skip(3, input)
+//
+//	// output: [4, 5]
+func Skip[T any](n int, in <-chan T) <-chan T {
+	count := 0
+	return FilterSequential(func(T) bool {
+		if count < n {
+			count++
+			return false
+		}
+		return true
+	}, in)
+}
+
+// SkipWhile drops messages of the input channel while the predicate returns true. The first message
+// for which the predicate returns false and all next messages are forwarded to the output channel.
+// If input channel is closed then output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan int, 4) with values [1, 2, 3, 1, 2]
+//
+//	output := SkipWhile(func(value int) bool {
+//	    return value < 3
+//	}, input)
+//
+//	// output: [3, 1, 2]
+func SkipWhile[T any](predicate func(T) bool, in <-chan T) <-chan T {
+	skipping := true
+	return FilterSequential(func(data T) bool {
+		if skipping && predicate(data) {
+			return false
+		}
+		skipping = false
+		return true
+	}, in)
+}
@@ -0,0 +1,117 @@
+package pipe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTake(t *testing.T) {
+	less3 := func(v int) bool {
+		return v < 3
+	}
+	collect := func(out <-chan int) []int {
+		var got []int
+		for v := range out {
+			got = append(got, v)
+		}
+		return got
+	}
+
+	// unread returns an unbuffered channel which sends n values, the returned channel
+	// is closed when all of them are read.
+	unread := func(n int) (<-chan int, <-chan struct{}) {
+		in := make(chan int)
+		done := make(chan struct{})
+		go func() {
+			for i := 1; i <= n; i++ {
+				in <- i
+			}
+			close(in)
+			close(done)
+		}()
+		return in, done
+	}
+	drained := func(t *testing.T, done <-chan struct{}) {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("input isn't drained")
+		}
+	}
+
+	t.Run("Take", func(t *testing.T) {
+		in, done := unread(100)
+		if got := collect(Take(3, in)); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+			t.Errorf("unexpected output %v", got)
+		}
+		drained(t, done)
+
+		if got := collect(Take(10, filled(4, 1, 2))); !reflect.DeepEqual(got, []int{1, 2}) {
+			t.Errorf("unexpected output %v", got)
+		}
+		if got := collect(Take(0, filled(4, 1, 2))); len(got) != 0 {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+
+	t.Run("TakeImmediately", func(t *testing.T) {
+		in := make(chan int)
+		out := Take(1, in)
+		in <- 1
+		<-out
+		select {
+		case _, ok := <-out:
+			if ok {
+				t.Error("unexpected message")
+			}
+		case <-time.After(time.Second):
+			t.Error("output isn't closed after n messages")
+		}
+		close(in)
+	})
+
+	t.Run("TakeWhile", func(t *testing.T) {
+		in, done := unread(100)
+		if got := collect(TakeWhile(less3, in)); !reflect.DeepEqual(got, []int{1, 2}) {
+			t.Errorf("unexpected output %v", got)
+		}
+		drained(t, done)
+	})
+
+	t.Run("TakeUntil", func(t *testing.T) {
+		in := make(chan int)
+		stop := make(chan struct{})
+		out := TakeUntil(stop, in)
+
+		in <- 1
+		if v := <-out; v != 1 {
+			t.Errorf("expected 1, got %d", v)
+		}
+		close(stop)
+		if got := collect(out); len(got) != 0 {
+			t.Errorf("unexpected output %v", got)
+		}
+
+		sent := make(chan struct{})
+		go func() {
+			in <- 2
+			in <- 3
+			close(in)
+			close(sent)
+		}()
+		drained(t, sent)
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		if got := collect(Skip(3, filled(8, 1, 2, 3, 4, 5))); !reflect.DeepEqual(got, []int{4, 5}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+
+	t.Run("SkipWhile", func(t *testing.T) {
+		if got := collect(SkipWhile(less3, filled(8, 1, 2, 3, 1, 2))); !reflect.DeepEqual(got, []int{3, 1, 2}) {
+			t.Errorf("unexpected output %v", got)
+		}
+	})
+}
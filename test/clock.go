@@ -0,0 +1,103 @@
+package test
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a manual clock, the time changes only by [Clock.Advance].
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*clockTimer
+}
+
+type clockTimer struct {
+	clock *Clock
+	at    time.Time
+	ch    chan time.Time
+}
+
+// NewClock returns a manual clock with the given current time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel which receives the time when the clock is advanced by the duration.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.newTimer(d).ch
+}
+
+// Timer is the same as pipe.Timer.
+type Timer = interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// NewTimer returns a timer which fires when the clock is advanced by the duration.
+func (c *Clock) NewTimer(d time.Duration) Timer {
+	return c.newTimer(d)
+}
+
+func (c *Clock) newTimer(d time.Duration) *clockTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &clockTimer{clock: c, at: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.ch <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Timers returns the number of timers which have not fired or been stopped.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// C returns the channel which receives the time when the timer fires.
+func (t *clockTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+// Stop removes the timer from the clock, it returns false if the timer has already fired or been stopped.
+func (t *clockTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by the duration and fires all expired timers.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+	n := 0
+	for ; n < len(c.timers) && !c.timers[n].at.After(c.now); n++ {
+		c.timers[n].ch <- c.now
+	}
+	c.timers = c.timers[n:]
+}
@@ -83,3 +83,12 @@ func WaitAny[T any](in ...<-chan T) <-chan struct{} {
 	}()
 	return q
 }
+
+// drain reads the input channel to the end.
+func drain[T any](in <-chan T) {
+	for {
+		if _, ok := <-in; !ok {
+			break
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package pipe
+
+import (
+	"container/heap"
+	"time"
+)
+
+type windowKind int
+
+const (
+	windowTumbling windowKind = iota
+	windowSliding
+	windowSession
+)
+
+// WindowSpec describes how messages are grouped into windows by time.
+// Use [TumblingWindow], [SlidingWindow] or [SessionWindow] to create it.
+type WindowSpec struct {
+	kind  windowKind
+	size  time.Duration
+	slide time.Duration
+	clock Clock
+}
+
+// TumblingWindow returns a spec of fixed-size, non-overlapping windows aligned to the size.
+//
+//	// size 1m: [00:00, 00:01) [00:01, 00:02) ...
+func TumblingWindow(size time.Duration) WindowSpec {
+	if size <= 0 {
+		panic("window size must be positive")
+	}
+	return WindowSpec{kind: windowTumbling, size: size, slide: size}
+}
+
+// SlidingWindow returns a spec of fixed-size windows which start every slide duration.
+// Windows overlap if the slide is less than the size, so a message can be aggregated into several windows.
+//
+//	// size 1m, slide 30s: [00:00, 00:01) [00:00:30, 00:01:30) [00:01, 00:02) ...
+func SlidingWindow(size, slide time.Duration) WindowSpec {
+	if size <= 0 || slide <= 0 {
+		panic("window size and slide must be positive")
+	}
+	return WindowSpec{kind: windowSliding, size: size, slide: slide}
+}
+
+// SessionWindow returns a spec of windows which are closed after the gap of inactivity of the key.
+//
+//	// gap 30s, events at 00:00, 00:00:20, 00:01: [00:00, 00:00:50) [00:01, 00:01:30)
+func SessionWindow(gap time.Duration) WindowSpec {
+	if gap <= 0 {
+		panic("window gap must be positive")
+	}
+	return WindowSpec{kind: windowSession, size: gap}
+}
+
+// WithClock returns a copy of the spec which uses the clock as a source of time.
+// By default the [SystemClock] is used.
+func (s WindowSpec) WithClock(clock Clock) WindowSpec {
+	s.clock = clock
+	return s
+}
+
+// WindowResult is the aggregated value of the closed window.
+type WindowResult[K comparable, A any] struct {
+	Key   K
+	Start time.Time
+	End   time.Time
+	Value A
+}
+
+// Window takes message, groups it into windows by the key and the time it was received, and aggregates
+// it into every window it belongs to by aggregate function. The aggregation of every window starts with
+// the zero value. When the window is closed, its result is sent to the output channel.
+// If input channel is closed then all open windows are closed and output channel is closed.
+// Creates a new channel with the same capacity as input.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Event, 4) with values received in the first minute [{a}, {b}, {a}]
+//
+//	output := Window(TumblingWindow(time.Minute), func(event Event) string {
+//	    return event.User
+//	}, func(count int, event Event) int {
+//	    return count + 1
+//	}, input)
+//
+//	// output: [{a 00:00 01:00 2}, {b 00:00 01:00 1}]
+func Window[T any, K comparable, A any](spec WindowSpec, key func(T) K, aggregate func(A, T) A, in <-chan T) <-chan WindowResult[K, A] {
+	out := make(chan WindowResult[K, A], cap(in))
+	clock := clockOrSystem(spec.clock)
+
+	go func() {
+		windows := newWindowSet[K, A](spec)
+		emit := func(results []WindowResult[K, A]) {
+			for i := range results {
+				out <- results[i]
+			}
+		}
+
+		var timer <-chan time.Time
+		var armed time.Time
+		for {
+			select {
+			case data, ok := <-in:
+				if !ok {
+					emit(windows.flush())
+					close(out)
+					return
+				}
+				now := clock.Now()
+				emit(windows.expire(now))
+				windows.add(key(data), now, func(acc A) A {
+					return aggregate(acc, data)
+				})
+			case <-timer:
+				timer = nil
+				emit(windows.expire(clock.Now()))
+			}
+
+			if next, ok := windows.next(); ok && (timer == nil || next.Before(armed)) {
+				armed = next
+				timer = clock.After(next.Sub(clock.Now()))
+			}
+		}
+	}()
+
+	return out
+}
+
+// windowID identifies the window of the key.
+type windowID[K comparable] struct {
+	key   K
+	start int64
+}
+
+// windowState is the open window.
+type windowState[K comparable, A any] struct {
+	WindowResult[K, A]
+	index int
+}
+
+// windowSet keeps open windows ordered by the end time.
+type windowSet[K comparable, A any] struct {
+	spec     WindowSpec
+	windows  map[windowID[K]]*windowState[K, A]
+	sessions map[K]*windowState[K, A]
+	queue    windowQueue[K, A]
+}
+
+func newWindowSet[K comparable, A any](spec WindowSpec) *windowSet[K, A] {
+	return &windowSet[K, A]{
+		spec:     spec,
+		windows:  map[windowID[K]]*windowState[K, A]{},
+		sessions: map[K]*windowState[K, A]{},
+	}
+}
+
+// add aggregates the message of the key received at the time into all windows it belongs to.
+// The time must not be less than the time of previous calls.
+func (s *windowSet[K, A]) add(key K, at time.Time, aggregate func(A) A) {
+	if s.spec.kind == windowSession {
+		if state, ok := s.sessions[key]; ok {
+			state.End = at.Add(s.spec.size)
+			state.Value = aggregate(state.Value)
+			heap.Fix(&s.queue, state.index)
+			return
+		}
+		state := &windowState[K, A]{WindowResult: WindowResult[K, A]{Key: key, Start: at, End: at.Add(s.spec.size)}}
+		state.Value = aggregate(state.Value)
+		s.sessions[key] = state
+		heap.Push(&s.queue, state)
+		return
+	}
+
+	for start := at.Truncate(s.spec.slide); start.Add(s.spec.size).After(at); start = start.Add(-s.spec.slide) {
+		id := windowID[K]{key: key, start: start.UnixNano()}
+		state, ok := s.windows[id]
+		if !ok {
+			state = &windowState[K, A]{WindowResult: WindowResult[K, A]{Key: key, Start: start, End: start.Add(s.spec.size)}}
+			s.windows[id] = state
+			heap.Push(&s.queue, state)
+		}
+		state.Value = aggregate(state.Value)
+	}
+}
+
+// next returns the end time of the window which will be closed first.
+func (s *windowSet[K, A]) next() (time.Time, bool) {
+	if len(s.queue) == 0 {
+		return time.Time{}, false
+	}
+	return s.queue[0].End, true
+}
+
+// expire closes all windows which end before or at the time.
+func (s *windowSet[K, A]) expire(now time.Time) []WindowResult[K, A] {
+	var results []WindowResult[K, A]
+	for len(s.queue) > 0 && !s.queue[0].End.After(now) {
+		results = append(results, s.pop())
+	}
+	return results
+}
+
+// flush closes all windows.
+func (s *windowSet[K, A]) flush() []WindowResult[K, A] {
+	results := make([]WindowResult[K, A], 0, len(s.queue))
+	for len(s.queue) > 0 {
+		results = append(results, s.pop())
+	}
+	return results
+}
+
+func (s *windowSet[K, A]) pop() WindowResult[K, A] {
+	state := heap.Pop(&s.queue).(*windowState[K, A])
+	if s.spec.kind == windowSession {
+		delete(s.sessions, state.Key)
+	} else {
+		delete(s.windows, windowID[K]{key: state.Key, start: state.Start.UnixNano()})
+	}
+	return state.WindowResult
+}
+
+// windowQueue is a min-heap of windows by the end time.
+type windowQueue[K comparable, A any] []*windowState[K, A]
+
+func (q windowQueue[K, A]) Len() int {
+	return len(q)
+}
+
+func (q windowQueue[K, A]) Less(i, j int) bool {
+	if q[i].End.Equal(q[j].End) {
+		return q[i].Start.Before(q[j].Start)
+	}
+	return q[i].End.Before(q[j].End)
+}
+
+func (q windowQueue[K, A]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *windowQueue[K, A]) Push(x any) {
+	state := x.(*windowState[K, A])
+	state.index = len(*q)
+	*q = append(*q, state)
+}
+
+func (q *windowQueue[K, A]) Pop() any {
+	old := *q
+	state := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return state
+}
+
+// timedEvent is the message with its event time.
+type timedEvent[T any] struct {
+	at   time.Time
+	seq  uint64
+	data T
+}
+
+// WindowEventTime takes message, groups it into windows by the key and the event time returned by
+// timestamp function, and aggregates it into every window it belongs to by aggregate function.
+// The aggregation of every window starts with the zero value.
+//
+// Messages may arrive out of order within the given delay. The watermark is the maximum event time
+// received so far minus the delay, it's the point in event time up to which all messages are expected
+// to be received. Messages are aggregated in the event time order once the watermark passes them,
+// and the window is closed and sent to the results channel when the watermark passes its end.
+// Messages with the event time before the watermark are late, they are sent to the late channel
+// and not aggregated. The clock of the spec is not used.
+//
+// If input channel is closed then all received messages are aggregated, all open windows are closed
+// and both output channels are closed.
+// Creates new channels with the same capacity as input.
+//
+// Be aware, both output channels must be read, otherwise the processing will be blocked.
+//
+// # Strategies
+//
+//   - Processing: Sequential
+//   - Closing: Single
+//   - Capacity: Same
+//
+// # Usages
+//
+//	// input := make(chan Event, 4) with event times [00:10, 00:50, 00:20, 01:30, 00:40, 02:00]
+//
+//	results, late := WindowEventTime(TumblingWindow(time.Minute), func(event Event) time.Time {
+//	    return event.Time
+//	}, 30*time.Second, func(event Event) string {
+//	    return event.User
+//	}, func(count int, event Event) int {
+//	    return count + 1
+//	}, input)
+//
+//	// results: [{a 00:00 01:00 3}, {a 01:00 02:00 1}, {a 02:00 03:00 1}]
+//	// late: [00:40] (the watermark is 01:00 after 01:30 is received)
+func WindowEventTime[T any, K comparable, A any](spec WindowSpec, timestamp func(T) time.Time, delay time.Duration, key func(T) K, aggregate func(A, T) A, in <-chan T) (results <-chan WindowResult[K, A], late <-chan T) {
+	out := make(chan WindowResult[K, A], cap(in))
+	lout := make(chan T, cap(in))
+
+	go func() {
+		windows := newWindowSet[K, A](spec)
+		pending := newPriorityQueue(func(a, b timedEvent[T]) bool {
+			if a.at.Equal(b.at) {
+				return a.seq < b.seq
+			}
+			return a.at.Before(b.at)
+		})
+		emit := func(results []WindowResult[K, A]) {
+			for i := range results {
+				out <- results[i]
+			}
+		}
+		// release aggregates pending messages with the event time before the watermark
+		release := func(watermark time.Time, all bool) {
+			for pending.Len() > 0 && (all || pending.peek().at.Before(watermark)) {
+				event := pending.pop()
+				emit(windows.expire(event.at))
+				windows.add(key(event.data), event.at, func(acc A) A {
+					return aggregate(acc, event.data)
+				})
+			}
+		}
+
+		var watermark time.Time
+		var seq uint64
+		started := false
+		for {
+			if data, ok := <-in; ok {
+				at := timestamp(data)
+				if started && at.Before(watermark) {
+					lout <- data
+					continue
+				}
+				if next := at.Add(-delay); !started || next.After(watermark) {
+					watermark = next
+					started = true
+				}
+				seq++
+				pending.push(timedEvent[T]{at: at, seq: seq, data: data})
+				release(watermark, false)
+				emit(windows.expire(watermark))
+			} else {
+				release(watermark, true)
+				emit(windows.flush())
+				close(out)
+				close(lout)
+				break
+			}
+		}
+	}()
+
+	return out, lout
+}
@@ -0,0 +1,164 @@
+package pipe
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/msacore/pipe/test"
+)
+
+type windowEvent struct {
+	User  string
+	Value int
+}
+
+// windowTester sends events to the window one by one and waits until every event is taken.
+type windowTester struct {
+	clock     *test.Clock
+	in        chan windowEvent
+	processed chan struct{}
+	out       <-chan WindowResult[string, int]
+}
+
+func newWindowTester(spec WindowSpec) *windowTester {
+	w := &windowTester{
+		clock:     test.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		in:        make(chan windowEvent),
+		processed: make(chan struct{}),
+	}
+	w.out = Window(spec.WithClock(w.clock), func(event windowEvent) string {
+		w.processed <- struct{}{}
+		return event.User
+	}, func(sum int, event windowEvent) int {
+		return sum + event.Value
+	}, w.in)
+	return w
+}
+
+func (w *windowTester) send(events ...windowEvent) {
+	for _, event := range events {
+		w.in <- event
+		<-w.processed
+	}
+}
+
+func (w *windowTester) receive(t *testing.T, n int) []WindowResult[string, int] {
+	results := make([]WindowResult[string, int], n)
+	for i := range results {
+		results[i] = <-w.out
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Key < results[j].Key
+	})
+	return results
+}
+
+func (w *windowTester) assert(t *testing.T, result WindowResult[string, int], key string, start, end time.Duration, value int) {
+	t.Helper()
+	origin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if result.Key != key || !result.Start.Equal(origin.Add(start)) || !result.End.Equal(origin.Add(end)) || result.Value != value {
+		t.Fatalf("expected {%s %v %v %d}, got {%s %v %v %d}", key, start, end, value,
+			result.Key, result.Start.Sub(origin), result.End.Sub(origin), result.Value)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	t.Run("Tumbling", func(t *testing.T) {
+		w := newWindowTester(TumblingWindow(time.Minute))
+		w.send(windowEvent{"a", 1}, windowEvent{"b", 2}, windowEvent{"a", 3})
+		w.clock.Advance(time.Minute)
+
+		results := w.receive(t, 2)
+		w.assert(t, results[0], "a", 0, time.Minute, 4)
+		w.assert(t, results[1], "b", 0, time.Minute, 2)
+
+		w.send(windowEvent{"a", 5})
+		close(w.in)
+
+		results = w.receive(t, 1)
+		w.assert(t, results[0], "a", time.Minute, 2*time.Minute, 5)
+		<-Wait(w.out)
+	})
+
+	t.Run("Sliding", func(t *testing.T) {
+		w := newWindowTester(SlidingWindow(2*time.Minute, time.Minute))
+		w.clock.Advance(30 * time.Second)
+		w.send(windowEvent{"a", 1})
+		w.clock.Advance(time.Minute)
+
+		results := w.receive(t, 1)
+		w.assert(t, results[0], "a", -time.Minute, time.Minute, 1)
+
+		w.send(windowEvent{"a", 2})
+
+		close(w.in)
+		results = w.receive(t, 2)
+		sort.Slice(results, func(i, j int) bool { return results[i].Start.Before(results[j].Start) })
+		w.assert(t, results[0], "a", 0, 2*time.Minute, 3)
+		w.assert(t, results[1], "a", time.Minute, 3*time.Minute, 2)
+		<-Wait(w.out)
+	})
+
+	t.Run("Session", func(t *testing.T) {
+		w := newWindowTester(SessionWindow(30 * time.Second))
+		w.send(windowEvent{"a", 1})
+		w.clock.Advance(20 * time.Second)
+		w.send(windowEvent{"a", 2}, windowEvent{"b", 3})
+		w.clock.Advance(20 * time.Second)
+		w.send(windowEvent{"b", 4})
+		w.clock.Advance(10 * time.Second)
+
+		results := w.receive(t, 1)
+		w.assert(t, results[0], "a", 0, 50*time.Second, 3)
+
+		close(w.in)
+		results = w.receive(t, 1)
+		w.assert(t, results[0], "b", 20*time.Second, 70*time.Second, 7)
+		<-Wait(w.out)
+	})
+}
+
+func TestWindowEventTime(t *testing.T) {
+	origin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	in := make(chan time.Duration, 8)
+	for _, at := range []time.Duration{10, 50, 20, 90, 40, 120} {
+		in <- at * time.Second
+	}
+	close(in)
+
+	results, late := WindowEventTime(TumblingWindow(time.Minute), func(at time.Duration) time.Time {
+		return origin.Add(at)
+	}, 30*time.Second, func(time.Duration) string {
+		return "a"
+	}, func(count int, _ time.Duration) int {
+		return count + 1
+	}, in)
+
+	lateEvents := make(chan []time.Duration)
+	go func() {
+		var events []time.Duration
+		for at := range late {
+			events = append(events, at)
+		}
+		lateEvents <- events
+	}()
+
+	w := &windowTester{}
+	expected := []int{3, 1, 1}
+	i := 0
+	for result := range results {
+		if i >= len(expected) {
+			t.Fatalf("unexpected result %v", result)
+		}
+		w.assert(t, result, "a", time.Duration(i)*time.Minute, time.Duration(i+1)*time.Minute, expected[i])
+		i++
+	}
+	if i != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), i)
+	}
+
+	if events := <-lateEvents; len(events) != 1 || events[0] != 40*time.Second {
+		t.Fatalf("expected late event at 40s, got %v", events)
+	}
+}